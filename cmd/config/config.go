@@ -4,6 +4,8 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"golang.org/x/exp/slog"
@@ -17,7 +19,35 @@ type Logger struct {
 
 // YoutubeAPI store secret keys and provide it for YoutubeAPIClient
 type YouTubeAPI struct {
-	APIKey string
+	APIKeys []string
+	// KeySelection is a policy of API key selection: round_robin or least_used
+	KeySelection string
+
+	// ListWorkers is a number of concurrent videos.list requests of one batch
+	ListWorkers int
+
+	// QuotaSoft and QuotaHard are daily limits of quota units. Zero means no limit.
+	QuotaSoft int64
+	QuotaHard int64
+
+	// MetadataTimeout limits videos.list requests of one batch
+	MetadataTimeout time.Duration
+	// ImageTimeout limits image downloads of one batch
+	ImageTimeout time.Duration
+	// ImageWorkers is a number of concurrent image downloads of one batch
+	ImageWorkers int
+
+	// BreakerThreshold is a number of consecutive failures opening circuit breaker of host
+	BreakerThreshold int
+	// BreakerCooldown is a time before probe request to host of open breaker
+	BreakerCooldown time.Duration
+
+	// RetryAttempts is a maximum number of attempts of request including the first one
+	RetryAttempts int
+	// RetryBaseDelay is a delay before the first retry, it doubles on every next one
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay limits delay between attempts
+	RetryMaxDelay time.Duration
 }
 
 // RedisClient configuration settings for Redis
@@ -27,6 +57,73 @@ type RedisClient struct {
 	PoolSize int
 }
 
+// MaxAge is a time while cached video is kept. Zero means forever.
+func (c *CacheBackend) MaxAge() time.Duration {
+	if c.TTL == 0 {
+		return 0
+	}
+	return c.TTL + c.StaleTTL
+}
+
+// CacheBackend selects implementation of cache.Cache
+type CacheBackend struct {
+	// Backend is "redis" (Redis and filesystem), "memory" (in-process LRU)
+	// or "tiered" (in-process LRU in front of Redis and filesystem)
+	Backend string
+	// MemoryEntries limits number of thumbnails in memory backend or hot tier
+	MemoryEntries int
+	// MemoryBytes limits size of images in memory backend or hot tier
+	MemoryBytes int64
+	// TTL is a time while cached video is fresh. Zero means forever.
+	TTL time.Duration
+	// StaleTTL is a time while expired video is still kept after TTL
+	StaleTTL time.Duration
+	// StaleWhileRevalidate serves stale video at once and refreshes it in background
+	StaleWhileRevalidate bool
+}
+
+// S3Storage configures S3-compatible media store
+type S3Storage struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+// MediaStorage configures blob store of redis and tiered cache backends
+type MediaStorage struct {
+	// Store is "fs" or "s3"
+	Store string
+	S3    *S3Storage
+
+	// MaxBytes limits size of media directory of fs store. Zero means no limit.
+	MaxBytes int64
+	// MaxFiles limits number of media files. Zero means no limit.
+	MaxFiles int
+	// Eviction is "lru" or "lfu"
+	Eviction string
+	// SweepInterval is a period of eviction
+	SweepInterval time.Duration
+	// GCInterval is a period of unreferenced blobs collection
+	GCInterval time.Duration
+	// GCGrace keeps recently written or read blobs from collection
+	GCGrace time.Duration
+}
+
+// HTTPServer configures HTTP server of media links
+type HTTPServer struct {
+	// Address of HTTP server. Empty disables it.
+	Address string
+	// PublicURL is a base of links given to clients
+	PublicURL string
+	// URLSecret signs links. Empty disables SIGNED_URL delivery.
+	URLSecret string
+	// URLTTL is a lifetime of links
+	URLTTL time.Duration
+}
+
 // Config is a configuration struct that store enviromental variables
 type Config struct {
 	ServerAddress    string
@@ -34,6 +131,9 @@ type Config struct {
 	Logger           *Logger
 	YouTube          *YouTubeAPI
 	Redis            *RedisClient
+	Cache            *CacheBackend
+	Media            *MediaStorage
+	HTTP             *HTTPServer
 }
 
 // Setup needs to set .env configuration Config
@@ -82,8 +182,98 @@ func Setup() *Config {
 
 	// YouTube
 	{
+		listWorkers, err := strconv.Atoi(os.Getenv("YOUTUBE_LIST_WORKERS"))
+		if err != nil || listWorkers <= 0 {
+			listWorkers = 4
+		}
+
+		imageWorkers, err := strconv.Atoi(os.Getenv("YOUTUBE_IMAGE_WORKERS"))
+		if err != nil || imageWorkers <= 0 {
+			imageWorkers = 8
+		}
+
+		quotaSoft, err := strconv.ParseInt(os.Getenv("YOUTUBE_QUOTA_SOFT"), 10, 64)
+		if err != nil {
+			quotaSoft = 8000
+		}
+
+		quotaHard, err := strconv.ParseInt(os.Getenv("YOUTUBE_QUOTA_HARD"), 10, 64)
+		if err != nil {
+			quotaHard = 10000
+		}
+
+		metadataTimeout, err := time.ParseDuration(os.Getenv("YOUTUBE_METADATA_TIMEOUT"))
+		if err != nil || metadataTimeout <= 0 {
+			metadataTimeout = 5 * time.Second
+		}
+
+		imageTimeout, err := time.ParseDuration(os.Getenv("YOUTUBE_IMAGE_TIMEOUT"))
+		if err != nil || imageTimeout <= 0 {
+			imageTimeout = 10 * time.Second
+		}
+
+		breakerThreshold, err := strconv.Atoi(os.Getenv("YOUTUBE_BREAKER_THRESHOLD"))
+		if err != nil || breakerThreshold <= 0 {
+			breakerThreshold = 5
+		}
+
+		breakerCooldown, err := time.ParseDuration(os.Getenv("YOUTUBE_BREAKER_COOLDOWN"))
+		if err != nil || breakerCooldown <= 0 {
+			breakerCooldown = 30 * time.Second
+		}
+
+		retryAttempts, err := strconv.Atoi(os.Getenv("YOUTUBE_RETRY_ATTEMPTS"))
+		if err != nil || retryAttempts <= 0 {
+			retryAttempts = 3
+		}
+
+		retryBaseDelay, err := time.ParseDuration(os.Getenv("YOUTUBE_RETRY_BASE_DELAY"))
+		if err != nil || retryBaseDelay <= 0 {
+			retryBaseDelay = 200 * time.Millisecond
+		}
+
+		retryMaxDelay, err := time.ParseDuration(os.Getenv("YOUTUBE_RETRY_MAX_DELAY"))
+		if err != nil || retryMaxDelay <= 0 {
+			retryMaxDelay = 5 * time.Second
+		}
+		// Delays longer than max one would be cut, so the first retry waits at most max delay
+		if retryMaxDelay < retryBaseDelay {
+			retryBaseDelay = retryMaxDelay
+		}
+
+		// Single key of YOUTUBE_APIKEY is kept for compatibility
+		var (
+			apiKeys []string
+			seen    = make(map[string]bool)
+		)
+		for _, key := range strings.Split(os.Getenv("YOUTUBE_APIKEYS")+","+os.Getenv("YOUTUBE_APIKEY"), ",") {
+			if key = strings.TrimSpace(key); key != "" && !seen[key] {
+				seen[key] = true
+				apiKeys = append(apiKeys, key)
+			}
+		}
+		// Pool without keys can't request anything and would look like spent quota
+		if len(apiKeys) == 0 {
+			panic("Set YOUTUBE_APIKEYS or YOUTUBE_APIKEY")
+		}
+
 		cfg.YouTube = &YouTubeAPI{
-			APIKey: os.Getenv("YOUTUBE_APIKEY"),
+			APIKeys:      apiKeys,
+			KeySelection: os.Getenv("YOUTUBE_KEY_SELECTION"),
+			ListWorkers:  listWorkers,
+			QuotaSoft:    quotaSoft,
+			QuotaHard:    quotaHard,
+
+			MetadataTimeout: metadataTimeout,
+			ImageTimeout:    imageTimeout,
+			ImageWorkers:    imageWorkers,
+
+			BreakerThreshold: breakerThreshold,
+			BreakerCooldown:  breakerCooldown,
+
+			RetryAttempts:  retryAttempts,
+			RetryBaseDelay: retryBaseDelay,
+			RetryMaxDelay:  retryMaxDelay,
 		}
 	}
 
@@ -99,5 +289,125 @@ func Setup() *Config {
 		}
 	}
 
+	// Cache
+	{
+		backend := os.Getenv("CACHE_BACKEND")
+		if backend == "" {
+			backend = "redis"
+		} else if backend != "redis" && backend != "memory" && backend != "tiered" {
+			panic("Unpredictable cache backend")
+		}
+
+		memoryEntries, err := strconv.Atoi(os.Getenv("CACHE_MEMORY_ENTRIES"))
+		if err != nil {
+			memoryEntries = 1000
+		}
+
+		memoryBytes, err := strconv.ParseInt(os.Getenv("CACHE_MEMORY_BYTES"), 10, 64)
+		if err != nil {
+			memoryBytes = 64 << 20
+		}
+
+		ttl, err := time.ParseDuration(os.Getenv("CACHE_TTL"))
+		if err != nil {
+			ttl = 24 * time.Hour
+		}
+
+		staleTTL, err := time.ParseDuration(os.Getenv("CACHE_STALE_TTL"))
+		if err != nil {
+			staleTTL = 7 * 24 * time.Hour
+		}
+
+		revalidate, err := strconv.ParseBool(os.Getenv("CACHE_STALE_WHILE_REVALIDATE"))
+		if err != nil {
+			revalidate = true
+		}
+
+		cfg.Cache = &CacheBackend{
+			Backend:              backend,
+			MemoryEntries:        memoryEntries,
+			MemoryBytes:          memoryBytes,
+			TTL:                  ttl,
+			StaleTTL:             staleTTL,
+			StaleWhileRevalidate: revalidate,
+		}
+	}
+
+	// Media
+	{
+		maxBytes, _ := strconv.ParseInt(os.Getenv("MEDIA_MAX_BYTES"), 10, 64)
+		maxFiles, _ := strconv.Atoi(os.Getenv("MEDIA_MAX_FILES"))
+
+		eviction := os.Getenv("MEDIA_EVICTION")
+		if eviction == "" {
+			eviction = "lru"
+		} else if eviction != "lru" && eviction != "lfu" {
+			panic("Unpredictable media eviction policy")
+		}
+
+		interval, err := time.ParseDuration(os.Getenv("MEDIA_SWEEP_INTERVAL"))
+		if err != nil {
+			interval = time.Minute
+		}
+
+		gcInterval, err := time.ParseDuration(os.Getenv("MEDIA_GC_INTERVAL"))
+		if err != nil || gcInterval <= 0 {
+			gcInterval = time.Hour
+		}
+
+		gcGrace, err := time.ParseDuration(os.Getenv("MEDIA_GC_GRACE"))
+		if err != nil {
+			gcGrace = 10 * time.Minute
+		}
+
+		store := os.Getenv("MEDIA_STORE")
+		if store == "" {
+			store = "fs"
+		} else if store != "fs" && store != "s3" {
+			panic("Unpredictable media store")
+		}
+
+		pathStyle, err := strconv.ParseBool(os.Getenv("S3_PATH_STYLE"))
+		if err != nil {
+			pathStyle = true
+		}
+
+		cfg.Media = &MediaStorage{
+			Store: store,
+			S3: &S3Storage{
+				Endpoint:  os.Getenv("S3_ENDPOINT"),
+				Region:    os.Getenv("S3_REGION"),
+				Bucket:    os.Getenv("S3_BUCKET"),
+				AccessKey: os.Getenv("S3_ACCESS_KEY"),
+				SecretKey: os.Getenv("S3_SECRET_KEY"),
+				PathStyle: pathStyle,
+			},
+			MaxBytes:      maxBytes,
+			MaxFiles:      maxFiles,
+			Eviction:      eviction,
+			SweepInterval: interval,
+			GCInterval:    gcInterval,
+			GCGrace:       gcGrace,
+		}
+	}
+
+	// HTTP
+	{
+		ttl, err := time.ParseDuration(os.Getenv("MEDIA_URL_TTL"))
+		if err != nil || ttl <= 0 {
+			ttl = 15 * time.Minute
+		}
+
+		cfg.HTTP = &HTTPServer{
+			Address:   os.Getenv("HTTP_ADDRESS"),
+			PublicURL: os.Getenv("HTTP_PUBLIC_URL"),
+			URLSecret: os.Getenv("MEDIA_URL_SECRET"),
+			URLTTL:    ttl,
+		}
+		if cfg.HTTP.PublicURL == "" {
+			cfg.HTTP.PublicURL = "http://" + cfg.HTTP.Address
+		}
+	}
+
 	return &cfg
 }
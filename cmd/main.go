@@ -13,6 +13,7 @@ import (
 	// Current module
 	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
 	"github.com/fluxx1on/thumbnails_microservice/internal"
+	"github.com/fluxx1on/thumbnails_microservice/internal/cache"
 	"github.com/fluxx1on/thumbnails_microservice/libs/logger/attrs"
 	"github.com/fluxx1on/thumbnails_microservice/libs/logger/handler"
 )
@@ -35,20 +36,23 @@ func main() {
 	slog.SetDefault(log)
 
 	// Redis
-	redis := redis.NewClient(
-		&redis.Options{
-			Addr:     cfg.Redis.Address,
-			DB:       cfg.Redis.DB,
-			PoolSize: cfg.Redis.PoolSize,
-		})
-	if _, err := redis.Ping().Result(); err != nil {
-		log.Error("Redis don't ping", err)
-		return
+	var redisConn *redis.Client
+	if cfg.Cache.Backend != cache.BackendMemory {
+		redisConn = redis.NewClient(
+			&redis.Options{
+				Addr:     cfg.Redis.Address,
+				DB:       cfg.Redis.DB,
+				PoolSize: cfg.Redis.PoolSize,
+			})
+		if _, err := redisConn.Ping().Result(); err != nil {
+			log.Error("Redis don't ping", err)
+			return
+		}
 	}
 
 	// gRPC Server starting
 	server := &internal.GRPC{}
-	server.StartUp(cfg, redis)
+	server.StartUp(cfg, redisConn)
 
 	<-signalCtx.Done()
 
@@ -61,7 +65,9 @@ func main() {
 
 	go func() {
 		server.Stop()
-		redis.Close()
+		if redisConn != nil {
+			redisConn.Close()
+		}
 		finished <- struct{}{}
 	}()
 
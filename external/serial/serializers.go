@@ -2,21 +2,69 @@ package serial
 
 type ThumbnailData []byte
 
+// Thumbnail resolution names used by YouTube Data API
+const (
+	ResolutionDefault  = "default"
+	ResolutionMedium   = "medium"
+	ResolutionHigh     = "high"
+	ResolutionStandard = "standard"
+	ResolutionMaxres   = "maxres"
+)
+
+// Resolutions lists all thumbnail resolutions from the lowest to the highest
+var Resolutions = []string{
+	ResolutionDefault,
+	ResolutionMedium,
+	ResolutionHigh,
+	ResolutionStandard,
+	ResolutionMaxres,
+}
+
+type ThumbnailSerializer struct {
+	Url    string `json:"url"`
+	Width  int32  `json:"width"`
+	Height int32  `json:"height"`
+}
+
 type SnippetSerializer struct {
-	ChannelTitle string `json:"channelTitle"`
-	Title        string `json:"title"`
-	Thumbnails   struct {
-		Maxres struct {
-			Url    string `json:"url"`
-			Width  int32  `json:"width"`
-			Height int32  `json:"height"`
-		} `json:"maxres"`
-	} `json:"thumbnails"`
+	ChannelId            string                         `json:"channelId"`
+	ChannelTitle         string                         `json:"channelTitle"`
+	Title                string                         `json:"title"`
+	Description          string                         `json:"description"`
+	PublishedAt          string                         `json:"publishedAt"` // RFC 3339
+	Tags                 []string                       `json:"tags"`
+	CategoryId           string                         `json:"categoryId"`
+	LiveBroadcastContent string                         `json:"liveBroadcastContent"`
+	DefaultLanguage      string                         `json:"defaultLanguage"`
+	Thumbnails           map[string]ThumbnailSerializer `json:"thumbnails"`
+}
+
+type ContentDetailsSerializer struct {
+	Duration string `json:"duration"` // ISO 8601, like PT1H2M3S
+}
+
+// StatisticsSerializer keeps counts as YouTube sends them, in decimal strings.
+// Hidden count is empty.
+type StatisticsSerializer struct {
+	ViewCount string `json:"viewCount"`
+	LikeCount string `json:"likeCount"`
+}
+
+type StatusSerializer struct {
+	PrivacyStatus string `json:"privacyStatus"`
 }
 
 type Item struct {
 	Id      string            `json:"id"`
 	Snippet SnippetSerializer `json:"snippet"`
+	Status  StatusSerializer  `json:"status"`
+
+	// ContentDetails and Statistics are nil unless their part is requested
+	ContentDetails *ContentDetailsSerializer `json:"contentDetails"`
+	Statistics     *StatisticsSerializer     `json:"statistics"`
+
+	// Resolution is selected thumbnail variant. Maxres if empty.
+	Resolution string `json:"-"`
 }
 
 func (i *Item) GetId() string {
@@ -31,16 +79,50 @@ func (i *Item) GetTitle() string {
 	return i.Snippet.Title
 }
 
+// IsPrivate reports that video is private
+func (i *Item) IsPrivate() bool {
+	return i.Status.PrivacyStatus == "private"
+}
+
+// GetResolution returns selected thumbnail variant
+func (i *Item) GetResolution() string {
+	if i.Resolution == "" {
+		return ResolutionMaxres
+	}
+	return i.Resolution
+}
+
+// Available returns thumbnail variants of video from the lowest to the highest
+func (i *Item) Available() []string {
+	available := make([]string, 0, len(Resolutions))
+	for _, res := range Resolutions {
+		if thumb, ok := i.Snippet.Thumbnails[res]; ok && thumb.Url != "" {
+			available = append(available, res)
+		}
+	}
+	return available
+}
+
+// WithResolution returns copy of Item with selected thumbnail variant
+func (i Item) WithResolution(res string) Item {
+	i.Resolution = res
+	return i
+}
+
+func (i *Item) thumbnail() ThumbnailSerializer {
+	return i.Snippet.Thumbnails[i.GetResolution()]
+}
+
 func (i *Item) GetUrl() string {
-	return i.Snippet.Thumbnails.Maxres.Url
+	return i.thumbnail().Url
 }
 
 func (i *Item) GetWidth() int32 {
-	return i.Snippet.Thumbnails.Maxres.Width
+	return i.thumbnail().Width
 }
 
 func (i *Item) GetHeight() int32 {
-	return i.Snippet.Thumbnails.Maxres.Height
+	return i.thumbnail().Height
 }
 
 type ListVideoSerializer struct {
@@ -54,6 +136,25 @@ func (l *ListVideoSerializer) IsEmpty() bool {
 	return len(l.Items) == 0
 }
 
+// ErrorSerializer is a body of failed YouTube API response
+type ErrorSerializer struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Errors  []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+// GetReason returns reason of the first error
+func (e *ErrorSerializer) GetReason() string {
+	if len(e.Error.Errors) == 0 {
+		return ""
+	}
+	return e.Error.Errors[0].Reason
+}
+
 type Video struct {
 	I    *Item
 	Data ThumbnailData
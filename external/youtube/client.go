@@ -3,6 +3,7 @@ package youtube
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 	"sync"
@@ -11,20 +12,35 @@ import (
 	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
 	"github.com/fluxx1on/thumbnails_microservice/external/serial"
 	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/quota"
+	"github.com/fluxx1on/thumbnails_microservice/libs/upstream"
 	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
 	"golang.org/x/exp/slog"
 )
 
 var (
 	curDir        = "/external/youtube"
-	timeout       = 15 * time.Second
-	baseVideosURL = "https://youtube.googleapis.com/youtube/v3/videos?part=snippet"
+	baseVideosURL = "https://youtube.googleapis.com/youtube/v3/videos?part=status"
+)
+
+const (
+	// MaxVideosPerRequest is a limit of IDs in one videos.list request
+	MaxVideosPerRequest = 50
+	// VideosListCost is a quota cost of videos.list request
+	VideosListCost = 1
+
+	defaultListWorkers     = 4
+	defaultImageWorkers    = 8
+	defaultMetadataTimeout = 5 * time.Second
+	defaultImageTimeout    = 10 * time.Second
 )
 
 type API interface {
-	GetVideos(...string) *serial.ListVideoSerializer
-	GetThumbnails(context.Context, ...string) []serial.ThumbnailData
-	GetVideoThumbnail(context.Context, ...string) ([]*proto.ThumbnailResponse, []string)
+	GetVideos(context.Context, utils.Parts, ...string) (*serial.ListVideoSerializer, map[string]error)
+	GetThumbnails(context.Context, map[ImageKey]string) (map[ImageKey]serial.ThumbnailData, map[ImageKey]error)
+	StreamThumbnails(context.Context, map[ImageKey]string, ImageFunc)
+	GetVideoThumbnail(context.Context, ...utils.Variant) ([]*proto.ThumbnailResponse, []Failure)
+	StreamVideoThumbnail(context.Context, ResolveFunc, ...utils.Variant)
 }
 
 var _ API = (*APIClient)(nil)
@@ -32,36 +48,177 @@ var _ API = (*APIClient)(nil)
 type APIClient struct {
 	httpClient *http.Client
 	cfg        *config.YouTubeAPI
+
+	keys *KeyPool
 }
 
 func NewAPIClient(YouTubeCfg *config.YouTubeAPI) *APIClient {
-	httpClient := &http.Client{}
-
 	return &APIClient{
 		cfg:        YouTubeCfg,
-		httpClient: httpClient,
+		httpClient: upstreamClient,
+		keys:       NewKeyPool(YouTubeCfg.APIKeys, YouTubeCfg.KeySelection),
 	}
 }
 
-func (y *APIClient) GetURL(videoID ...string) string {
+// SetQuota enables quota accounting. Every API key has its own usage.
+func (y *APIClient) SetQuota(tracker *quota.Tracker) {
+	y.keys.setQuota(tracker)
+}
+
+// QuotaLevel returns state of daily quota usage of the least used API key
+func (y *APIClient) QuotaLevel() quota.Level {
+	return y.keys.Level(time.Now())
+}
+
+// KeyStats returns usage statistic of API keys
+func (y *APIClient) KeyStats() []KeyStats {
+	return y.keys.Stats()
+}
+
+// metadataTimeout is a deadline of videos.list phase of batch
+func (y *APIClient) metadataTimeout() time.Duration {
+	if y.cfg.MetadataTimeout <= 0 {
+		return defaultMetadataTimeout
+	}
+	return y.cfg.MetadataTimeout
+}
+
+// imageTimeout is a deadline of image download phase of batch
+func (y *APIClient) imageTimeout() time.Duration {
+	if y.cfg.ImageTimeout <= 0 {
+		return defaultImageTimeout
+	}
+	return y.cfg.ImageTimeout
+}
+
+// GetURL returns videos.list url requesting meta data parts of videos with API key
+func (y *APIClient) GetURL(key string, parts utils.Parts, videoID ...string) string {
 	builder := &strings.Builder{}
 
 	builder.WriteString(baseVideosURL)
+	for _, name := range (parts | utils.NewParts()).Names() {
+		builder.WriteString("," + name)
+	}
 	for _, str := range videoID {
 		builder.WriteString("&id=" + str)
 	}
 
-	return builder.String() + "&key=" + y.cfg.APIKey
+	return builder.String() + "&key=" + key
+}
+
+// ChunkIDs splits video IDs into chunks of at most size IDs
+func ChunkIDs(videoID []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(videoID)+size-1)/size)
+	for size < len(videoID) {
+		videoID, chunks = videoID[size:], append(chunks, videoID[:size:size])
+	}
+	if len(videoID) != 0 {
+		chunks = append(chunks, videoID)
+	}
+	return chunks
+}
+
+// GetVideos requests videos meta data by chunks of MaxVideosPerRequest IDs.
+// Chunks are requested concurrently by bounded number of workers.
+// Returned map keeps error of failed chunk by its video IDs; error is always *Error.
+// Videos missing in response and not failed don't exist or private.
+// Chunks not requested before deadline of metadata phase or cancel of ctx fail, others are kept.
+func (y *APIClient) GetVideos(ctx context.Context, parts utils.Parts, videoID ...string) (
+	*serial.ListVideoSerializer, map[string]error) {
+	ctx, cancel := context.WithTimeout(ctx, y.metadataTimeout())
+	defer cancel()
+
+	var (
+		chunks  = ChunkIDs(videoID, MaxVideosPerRequest)
+		results = make([]*serial.ListVideoSerializer, len(chunks))
+		errList = make([]error, len(chunks))
+		workers = y.cfg.ListWorkers
+		wg      sync.WaitGroup
+	)
+	if workers <= 0 {
+		workers = defaultListWorkers
+	}
+
+	semaphore := make(chan struct{}, workers)
+	for index, chunk := range chunks {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			errList[index] = networkError(ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(index int, chunk []string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			results[index], errList[index] = y.listVideos(ctx, parts, chunk...)
+		}(index, chunk)
+	}
+	wg.Wait()
+
+	var (
+		videos = &serial.ListVideoSerializer{}
+		failed map[string]error
+	)
+	for index, chunk := range chunks {
+		if errList[index] == nil {
+			videos.Items = append(videos.Items, results[index].Items...)
+			continue
+		}
+
+		if failed == nil {
+			failed = make(map[string]error, len(chunk))
+		}
+		for _, id := range chunk {
+			failed[id] = errList[index]
+		}
+	}
+
+	return videos, failed
 }
 
-func (y *APIClient) GetVideos(videoID ...string) *serial.ListVideoSerializer {
-	var URL = y.GetURL(videoID...)
+// listVideos requests meta data of at most MaxVideosPerRequest videos.
+// Request is retried with another API key if key is quarantined by response.
+// Every attempt of request, including retries of transport, is charged to quota of its key.
+// Returned error is always *Error.
+func (y *APIClient) listVideos(ctx context.Context, parts utils.Parts, videoID ...string) (
+	*serial.ListVideoSerializer, error) {
+	tried := make(map[*apiKey]bool, len(y.keys.keys))
+	for {
+		// Quota isn't charged for request that can't be made
+		if err := ctx.Err(); err != nil {
+			return nil, networkError(err)
+		}
+		now := time.Now()
 
+		// Nothing is requested if every key is out of quota
+		key, err := y.keys.acquire(VideosListCost, now, tried)
+		if err != nil {
+			return nil, err
+		}
+		tried[key] = true
+
+		// Retries made by transport cost quota as much as the first attempt
+		attemptCtx := upstream.WithRetryHook(ctx, func() error {
+			return key.charge(VideosListCost, time.Now())
+		})
+		videos, err := y.requestVideos(attemptCtx, y.GetURL(key.value, parts, videoID...))
+		if !y.keys.release(key, err, now) {
+			return videos, err
+		}
+	}
+}
+
+// requestVideos makes videos.list request. Returned error is always *Error.
+func (y *APIClient) requestVideos(ctx context.Context, URL string) (*serial.ListVideoSerializer, error) {
 	// Make request
-	req, err := http.NewRequest("GET", URL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", URL, nil)
 	if err != nil {
 		slog.Error("Unknown", err, curDir)
-		return nil
+		return nil, &Error{Code: proto.ErrorCode_UPSTREAM_ERROR, Err: err}
 	}
 
 	// Headers
@@ -71,105 +228,147 @@ func (y *APIClient) GetVideos(videoID ...string) *serial.ListVideoSerializer {
 	resp, err := y.httpClient.Do(req)
 	if err != nil {
 		slog.Error("YouTube no respond", err, curDir)
-		return nil
+		return nil, networkError(err)
 	}
 	defer resp.Body.Close()
 
 	// Check the response status code
 	if resp.StatusCode != http.StatusOK {
-		slog.Error("YouTube request failed", resp.StatusCode, curDir)
-		return nil
+		ytErr := statusError(resp)
+		slog.Error("YouTube request failed", resp.StatusCode, ytErr, curDir)
+		return nil, ytErr
 	}
 
 	// Deserializing response.Body
 	var videos serial.ListVideoSerializer
 	err = json.NewDecoder(resp.Body).Decode(&videos)
-	if err != nil || videos.IsEmpty() {
-		slog.Debug("Errors while decoding", URL)
-		return nil
+	if err != nil {
+		slog.Debug("Errors while decoding", err)
+		if ctx.Err() != nil {
+			return nil, networkError(ctx.Err())
+		}
+		return nil, &Error{Code: proto.ErrorCode_UPSTREAM_ERROR, Err: err}
 	}
 
-	return &videos
+	return &videos, nil
 }
 
-func (y *APIClient) GetThumbnails(ctx context.Context, imageURL ...string) []serial.ThumbnailData {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+// ResolveFunc receives response or error of variant
+type ResolveFunc func(variant utils.Variant, resp *proto.ThumbnailResponse, err error)
 
+// GetVideoThumbnail gets videos meta data and thumbnails.
+// Every variant gets the best available resolution according to its fallback policy.
+// Images of metadata only variants aren't downloaded.
+func (y *APIClient) GetVideoThumbnail(ctx context.Context, variants ...utils.Variant) (
+	[]*proto.ThumbnailResponse, []Failure) {
 	var (
-		wg            sync.WaitGroup
-		thumbnailList = make([]serial.ThumbnailData, 0, len(imageURL))
+		failures              []Failure
+		thumbnailResponseList []*proto.ThumbnailResponse
 	)
 
-	for _, url := range imageURL {
-		wg.Add(1)
-		go func(ctx context.Context, url string) {
-			defer wg.Done()
+	y.StreamVideoThumbnail(ctx, func(variant utils.Variant, resp *proto.ThumbnailResponse, err error) {
+		if err != nil {
+			failures = append(failures, Failure{Variant: variant, Err: err})
+			return
+		}
+		thumbnailResponseList = append(thumbnailResponseList, resp)
+	}, variants...)
 
-			thumbnail, err := GetImage(url)
-			if err != nil { // requires that thumbnail is nil
-				slog.Debug("Bad response from YT API", err)
-			}
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				thumbnailList = append(thumbnailList, thumbnail)
-			}
-		}(ctx, url)
+	return thumbnailResponseList, failures
+}
+
+// StreamVideoThumbnail gets videos like GetVideoThumbnail, but passes every variant to resolved
+// as soon as its response or error is known. Calls of resolved are sequential
+// and are made by the calling goroutine.
+func (y *APIClient) StreamVideoThumbnail(ctx context.Context, resolved ResolveFunc, variants ...utils.Variant) {
+	if len(variants) == 0 {
+		return
 	}
 
-	wg.Wait()
+	var (
+		videoID   = make([]string, 0, len(variants))
+		requested = make(map[string]bool, len(variants))
+		parts     utils.Parts
+	)
 
-	// Some thumbnails didn't download. Error needs to be provided to user.
-	if ctx.Err() != nil {
-		slog.Error("Request timeout", ctx.Err(), curDir)
-		return nil
+	// Meta data parts are requested for all videos at once
+	for _, variant := range variants {
+		parts |= variant.Parts
+		if !requested[variant.ID] {
+			requested[variant.ID] = true
+			videoID = append(videoID, variant.ID)
+		}
 	}
 
-	// Clear downloading
-	return thumbnailList
-}
+	// Failed chunks affect their own videos only
+	videos, failed := y.GetVideos(ctx, parts, videoID...)
 
-// GetVideoThumbnail gets videos meta data and thumbnails
-func (y *APIClient) GetVideoThumbnail(ctx context.Context, videoID ...string) (
-	[]*proto.ThumbnailResponse, []string) {
-	if len(videoID) == 0 {
-		return nil, nil
+	itemByID := make(map[string]*serial.Item, len(videos.Items))
+	for i := range videos.Items {
+		itemByID[videos.Items[i].GetId()] = &videos.Items[i]
 	}
 
+	// Select resolution of every variant
 	var (
-		errListID             []string
-		thumbnailResponseList []*proto.ThumbnailResponse
+		items    = make([]serial.Item, 0, len(variants))
+		selected = make([]utils.Variant, 0, len(variants))
+		byKey    = make(map[ImageKey][]int, len(variants)) // indexes of items by their image
+		images   = make(map[ImageKey]string, len(variants))
 	)
+	for _, variant := range variants {
+		if err, ok := failed[variant.ID]; ok {
+			resolved(variant, nil, err)
+			continue
+		}
 
-	videos := y.GetVideos(videoID...)
-	if videos == nil { // requires that videos are not nil
-		return nil, videoID
-	}
+		item, ok := itemByID[variant.ID]
+		if !ok {
+			resolved(variant, nil, ErrNotFound)
+			continue
+		}
+		if item.IsPrivate() {
+			resolved(variant, nil, ErrPrivate)
+			continue
+		}
+
+		res, ok := utils.PickResolution(utils.ParseResolutions(item.Available()...),
+			variant.Resolution, variant.Fallback)
+		if !ok {
+			resolved(variant, nil, &Error{
+				Code: proto.ErrorCode_NOT_FOUND,
+				Err:  fmt.Errorf("no thumbnail in %s resolution", utils.ResolutionName(variant.Resolution)),
+			})
+			continue
+		}
 
-	imageUrls := make([]string, 0, len(videos.Items))
-	for _, item := range videos.Items {
-		imageUrls = append(imageUrls, item.GetUrl())
+		// Meta data is answered at once, variants of the same image share its download
+		selectedItem := item.WithResolution(utils.ResolutionName(res))
+		if variant.MetadataOnly {
+			resolved(variant, utils.NewThumbnailResponse(&serial.Video{I: &selectedItem}), nil)
+			continue
+		}
+		key := ImageKey{ID: variant.ID, Resolution: res}
+		images[key] = selectedItem.GetUrl()
+		byKey[key] = append(byKey[key], len(items))
+		items = append(items, selectedItem)
+		selected = append(selected, variant)
 	}
 
-	thumbnails := y.GetThumbnails(ctx, imageUrls...)
-	if thumbnails == nil { // requires that thumbnails are not nil
-		return nil, videoID
+	if len(images) == 0 {
+		return
 	}
 
-	for i := range videoID {
-		if i < len(videos.Items) && thumbnails[i] != nil {
+	y.StreamThumbnails(ctx, images, func(key ImageKey, data serial.ThumbnailData, err error) {
+		for _, i := range byKey[key] {
+			if err != nil {
+				resolved(selected[i], nil, err)
+				continue
+			}
 			video := &serial.Video{
-				I:    &videos.Items[i],
-				Data: thumbnails[i],
+				I:    &items[i],
+				Data: data,
 			}
-			newThumbnail := utils.NewThumbnailResponse(video)
-			thumbnailResponseList = append(thumbnailResponseList, newThumbnail)
-		} else {
-			errListID = append(errListID, videoID[i])
+			resolved(selected[i], utils.NewThumbnailResponse(video), nil)
 		}
-	}
-
-	return thumbnailResponseList, errListID
+	})
 }
@@ -0,0 +1,115 @@
+package youtube
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/serial"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"golang.org/x/exp/slog"
+)
+
+// ImageKey identifies thumbnail image of video
+type ImageKey struct {
+	ID         string
+	Resolution proto.Resolution
+}
+
+// imageResult is a downloaded image or failure of key
+type imageResult struct {
+	key  ImageKey
+	data serial.ThumbnailData
+	err  error
+}
+
+// ImageFunc receives downloaded image or error of key
+type ImageFunc func(key ImageKey, data serial.ThumbnailData, err error)
+
+// imageWorkers is a number of concurrent image downloads of one batch
+func (y *APIClient) imageWorkers() int {
+	if y.cfg.ImageWorkers <= 0 {
+		return defaultImageWorkers
+	}
+	return y.cfg.ImageWorkers
+}
+
+// GetThumbnails downloads images by urls of keys with bounded number of workers.
+// Every key gets either image or error; error is always *Error.
+// Images downloaded before deadline of image phase or cancel of ctx are kept.
+func (y *APIClient) GetThumbnails(ctx context.Context, images map[ImageKey]string) (
+	map[ImageKey]serial.ThumbnailData, map[ImageKey]error) {
+	var (
+		thumbnails = make(map[ImageKey]serial.ThumbnailData, len(images))
+		errByKey   = make(map[ImageKey]error)
+	)
+	y.StreamThumbnails(ctx, images, func(key ImageKey, data serial.ThumbnailData, err error) {
+		if err != nil {
+			errByKey[key] = err
+			return
+		}
+		thumbnails[key] = data
+	})
+
+	return thumbnails, errByKey
+}
+
+// StreamThumbnails downloads images like GetThumbnails, but passes every key to loaded
+// as soon as its image is downloaded or failed. Calls of loaded are sequential
+// and are made by the calling goroutine.
+func (y *APIClient) StreamThumbnails(ctx context.Context, images map[ImageKey]string, loaded ImageFunc) {
+	ctx, cancel := context.WithTimeout(ctx, y.imageTimeout())
+	defer cancel()
+
+	workers := y.imageWorkers()
+	if workers > len(images) {
+		workers = len(images)
+	}
+
+	var (
+		jobs    = make(chan ImageKey)
+		results = make(chan imageResult)
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				data, err := GetImage(ctx, images[key])
+				if err != nil { // requires that data is nil
+					slog.Debug("Bad response from YT API", key.ID, err)
+				}
+				results <- imageResult{key: key, data: data, err: err}
+			}
+		}()
+	}
+
+	// Keys not taken by workers before ctx is done fail without request
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(results)
+		}()
+		for key := range images {
+			select {
+			case jobs <- key:
+			case <-ctx.Done():
+				results <- imageResult{key: key, err: networkError(ctx.Err())}
+			}
+		}
+	}()
+
+	var failed int
+	for result := range results {
+		if result.err != nil {
+			failed++
+		}
+		loaded(result.key, result.data, result.err)
+	}
+
+	if ctx.Err() != nil {
+		slog.Error("Request timeout", ctx.Err(), failed, curDir)
+	}
+}
@@ -0,0 +1,124 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/serial"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+var (
+	ErrNotFound = &Error{Code: proto.ErrorCode_NOT_FOUND, Err: errors.New("video not found")}
+	ErrPrivate  = &Error{Code: proto.ErrorCode_PRIVATE, Err: errors.New("video is private")}
+)
+
+// quotaReasons are YouTube error reasons about exhausted quota
+var quotaReasons = map[string]bool{
+	"quotaExceeded":      true,
+	"dailyLimitExceeded": true,
+	"rateLimitExceeded":  true,
+}
+
+// keyReasons are reasons of rejected API key
+var keyReasons = map[string]bool{
+	"keyInvalid": true,
+	"keyExpired": true,
+}
+
+// dailyQuotaReasons are reasons of exhausted daily quota, others are rate limits
+var dailyQuotaReasons = map[string]bool{
+	"quotaExceeded":      true,
+	"dailyLimitExceeded": true,
+}
+
+// Error is a failure of YouTube request classified by ErrorCode
+type Error struct {
+	Code proto.ErrorCode
+	Err  error
+
+	// Reason is a reason of YouTube error response if any
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", e.Code, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is compares errors by code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// ExhaustsQuota reports that error is caused by spent daily quota
+func (e *Error) ExhaustsQuota() bool {
+	return dailyQuotaReasons[e.Reason]
+}
+
+// RejectsKey reports that API key of request isn't accepted
+func (e *Error) RejectsKey() bool {
+	return keyReasons[e.Reason]
+}
+
+// ErrorCode returns code of error. UPSTREAM_ERROR if error isn't classified.
+func ErrorCode(err error) proto.ErrorCode {
+	var ytErr *Error
+	if errors.As(err, &ytErr) {
+		return ytErr.Code
+	}
+	return proto.ErrorCode_UPSTREAM_ERROR
+}
+
+// networkError classifies error of http.Client
+func networkError(err error) *Error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &Error{Code: proto.ErrorCode_UPSTREAM_TIMEOUT, Err: err}
+	}
+	return &Error{Code: proto.ErrorCode_UPSTREAM_ERROR, Err: err}
+}
+
+// statusError classifies non-200 response by status code and YouTube error reason
+func statusError(resp *http.Response) *Error {
+	var (
+		body   serial.ErrorSerializer
+		reason string
+	)
+
+	if data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); err == nil {
+		if json.Unmarshal(data, &body) == nil {
+			reason = body.GetReason()
+		}
+	}
+
+	err := fmt.Errorf("status code: %d %s", resp.StatusCode, reason)
+	code := proto.ErrorCode_UPSTREAM_ERROR
+	switch {
+	case quotaReasons[reason]:
+		code = proto.ErrorCode_QUOTA_EXCEEDED
+	case resp.StatusCode == http.StatusNotFound:
+		code = proto.ErrorCode_NOT_FOUND
+	case resp.StatusCode == http.StatusTooManyRequests:
+		code = proto.ErrorCode_QUOTA_EXCEEDED
+	case resp.StatusCode == http.StatusGatewayTimeout:
+		code = proto.ErrorCode_UPSTREAM_TIMEOUT
+	}
+	return &Error{Code: code, Err: err, Reason: reason}
+}
+
+// Failure is a variant that wasn't downloaded
+type Failure struct {
+	Variant utils.Variant
+	Err     error
+}
@@ -1,22 +1,34 @@
 package youtube
 
 import (
-	"fmt"
+	"context"
 	"io"
 	"net/http"
 
 	"github.com/fluxx1on/thumbnails_microservice/external/serial"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
 )
 
-func GetImage(url string) (serial.ThumbnailData, error) {
-	response, err := http.Get(url)
+// GetImage downloads thumbnail. Temporary failures are retried until ctx is done.
+// Returned error is always *Error.
+func GetImage(ctx context.Context, url string) (serial.ThumbnailData, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return nil, &Error{Code: proto.ErrorCode_UPSTREAM_ERROR, Err: err}
 	}
 
+	response, err := upstreamClient.Do(req)
+	if err != nil {
+		return nil, networkError(err)
+	}
+	defer response.Body.Close()
+
 	if response.StatusCode == 200 {
 		body, err := io.ReadAll(response.Body)
-		return body, err
+		if err != nil {
+			return nil, networkError(err)
+		}
+		return body, nil
 	}
-	return nil, fmt.Errorf("status code: %d", response.StatusCode)
+	return nil, statusError(response)
 }
@@ -0,0 +1,223 @@
+package youtube
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/quota"
+	"golang.org/x/exp/slog"
+)
+
+// Key selection policies of KeyPool
+const (
+	SelectRoundRobin = "round_robin"
+	SelectLeastUsed  = "least_used"
+)
+
+var ErrNoKeys = &Error{Code: proto.ErrorCode_QUOTA_EXCEEDED, Err: errors.New("no API key available")}
+
+// apiKey is a YouTube Data API key of pool
+type apiKey struct {
+	value string
+	// id is a name of key safe for logs and Redis keys
+	id string
+
+	// quota accounts units spent by key. Nil means no accounting.
+	quota *quota.Tracker
+
+	requests, failures atomic.Uint64
+	// quarantinedUntil is unix time when key is used again
+	quarantinedUntil atomic.Int64
+}
+
+func newAPIKey(value string) *apiKey {
+	sum := sha256.Sum256([]byte(value))
+	return &apiKey{
+		value: value,
+		id:    hex.EncodeToString(sum[:6]),
+	}
+}
+
+func (k *apiKey) quarantined(now time.Time) bool {
+	return now.Unix() < k.quarantinedUntil.Load()
+}
+
+// quarantine excludes key from selection until quota is reset
+func (k *apiKey) quarantine(now time.Time, err error) {
+	until := quota.Reset(now)
+	if k.quarantinedUntil.Swap(until.Unix()) < until.Unix() {
+		slog.Warn("API key quarantined", k.id, until.Format(time.RFC3339), err, curDir)
+	}
+}
+
+// charge spends units of key quota for one request attempt.
+// Key is quarantined if its quota doesn't allow it.
+func (k *apiKey) charge(units int64, now time.Time) error {
+	if k.quota != nil {
+		if err := k.quota.Charge(units, now); err != nil {
+			k.quarantine(now, err)
+			return err
+		}
+	}
+	k.requests.Add(1)
+	return nil
+}
+
+// KeyStats is a usage statistic of API key
+type KeyStats struct {
+	Key              string
+	Requests         uint64
+	Failures         uint64
+	QuotaUsed        int64
+	QuarantinedUntil time.Time // zero if key is in use
+}
+
+func (s KeyStats) String() string {
+	str := fmt.Sprintf("%s: requests %d; failures %d; quota used %d;", s.Key, s.Requests, s.Failures, s.QuotaUsed)
+	if !s.QuarantinedUntil.IsZero() {
+		str += " quarantined until " + s.QuarantinedUntil.Format(time.RFC3339) + ";"
+	}
+	return str
+}
+
+// KeyPool selects API keys for requests.
+// Key rejected by YouTube or out of quota is quarantined until quota reset.
+type KeyPool struct {
+	keys   []*apiKey
+	policy string
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewKeyPool creates pool of keys selected by policy, round robin by default
+func NewKeyPool(keys []string, policy string) *KeyPool {
+	pool := &KeyPool{policy: policy}
+	for _, value := range keys {
+		pool.keys = append(pool.keys, newAPIKey(value))
+	}
+	return pool
+}
+
+// setQuota gives every key its own usage of tracker
+func (p *KeyPool) setQuota(tracker *quota.Tracker) {
+	for _, key := range p.keys {
+		key.quota = tracker.Scoped(key.id)
+	}
+}
+
+// candidates returns keys in order they should be tried
+func (p *KeyPool) candidates() []*apiKey {
+	ordered := make([]*apiKey, 0, len(p.keys))
+	if len(p.keys) == 0 {
+		return ordered
+	}
+
+	if p.policy == SelectLeastUsed {
+		ordered = append(ordered, p.keys...)
+		for i := 1; i < len(ordered); i++ {
+			for j := i; j > 0 && ordered[j].requests.Load() < ordered[j-1].requests.Load(); j-- {
+				ordered[j], ordered[j-1] = ordered[j-1], ordered[j]
+			}
+		}
+		return ordered
+	}
+
+	p.mu.Lock()
+	start := p.next
+	p.next = (p.next + 1) % len(p.keys)
+	p.mu.Unlock()
+
+	for i := range p.keys {
+		ordered = append(ordered, p.keys[(start+i)%len(p.keys)])
+	}
+	return ordered
+}
+
+// acquire selects key for request costing units and charges its quota.
+// Keys skipped in previous attempts of the request are passed by tried.
+func (p *KeyPool) acquire(units int64, now time.Time, tried map[*apiKey]bool) (*apiKey, error) {
+	for _, key := range p.candidates() {
+		if tried[key] || key.quarantined(now) {
+			continue
+		}
+		if key.charge(units, now) != nil {
+			continue
+		}
+		return key, nil
+	}
+	return nil, ErrNoKeys
+}
+
+// release records result of request made with key.
+// It reports that request may be retried with another key.
+func (p *KeyPool) release(key *apiKey, err error, now time.Time) bool {
+	if err == nil {
+		return false
+	}
+	key.failures.Add(1)
+
+	var ytErr *Error
+	if !errors.As(err, &ytErr) {
+		return false
+	}
+
+	switch {
+	case ytErr.ExhaustsQuota():
+		if key.quota != nil {
+			key.quota.Exhaust(now)
+		}
+		key.quarantine(now, err)
+		return true
+	case ytErr.RejectsKey():
+		key.quarantine(now, err)
+		return true
+	}
+	return false
+}
+
+// Level returns the lowest quota level of keys in use
+func (p *KeyPool) Level(now time.Time) quota.Level {
+	level := quota.LevelHard
+	for _, key := range p.keys {
+		if key.quarantined(now) {
+			continue
+		}
+		if key.quota == nil {
+			return quota.LevelNormal
+		}
+		if keyLevel := key.quota.Level(now); keyLevel < level {
+			level = keyLevel
+		}
+	}
+	return level
+}
+
+// Stats returns usage statistic of every key
+func (p *KeyPool) Stats() []KeyStats {
+	var (
+		now   = time.Now()
+		stats = make([]KeyStats, 0, len(p.keys))
+	)
+	for _, key := range p.keys {
+		keyStats := KeyStats{
+			Key:      key.id,
+			Requests: key.requests.Load(),
+			Failures: key.failures.Load(),
+		}
+		if key.quota != nil {
+			keyStats.QuotaUsed = key.quota.Used(now)
+		}
+		if key.quarantined(now) {
+			keyStats.QuarantinedUntil = time.Unix(key.quarantinedUntil.Load(), 0)
+		}
+		stats = append(stats, keyStats)
+	}
+	return stats
+}
@@ -0,0 +1,38 @@
+package youtube
+
+import (
+	"net/http"
+
+	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
+	"github.com/fluxx1on/thumbnails_microservice/libs/upstream"
+)
+
+// Hosts of YouTube requests
+const (
+	VideosHost = "youtube.googleapis.com"
+	ImagesHost = "i.ytimg.com"
+)
+
+// Breakers are circuit breakers of YouTube hosts shared by API client and image downloads.
+// They never open until SetupTransport configures them.
+var Breakers = upstream.NewBreakers(upstream.BreakerConfig{}, VideosHost, ImagesHost)
+
+// transport makes single attempt until SetupTransport sets retry policy
+var transport = upstream.NewTransport(Breakers, upstream.RetryPolicy{})
+
+// upstreamClient retries temporary failures of YouTube requests
+var upstreamClient = &http.Client{Transport: transport}
+
+// SetupTransport applies breaker and retry settings to YouTube requests.
+// It must be called before requests are made.
+func SetupTransport(cfg *config.YouTubeAPI) {
+	Breakers.Configure(upstream.BreakerConfig{
+		Threshold: cfg.BreakerThreshold,
+		Cooldown:  cfg.BreakerCooldown,
+	})
+	transport.Policy = upstream.RetryPolicy{
+		Attempts:  cfg.RetryAttempts,
+		BaseDelay: cfg.RetryBaseDelay,
+		MaxDelay:  cfg.RetryMaxDelay,
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+// Backends of Cache
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+	BackendTiered = "tiered"
+)
+
+// ErrUnavailable is a failure of cache backend. Lookup failed by it isn't a miss.
+var ErrUnavailable = errors.New("cache is unavailable")
+
+type Cache interface {
+	// Get returns nil response if variant isn't cached
+	Get(context.Context, utils.Variant) (*proto.ThumbnailResponse, error)
+	// GetSeries returns cached responses and variants that aren't cached.
+	// Variants which lookup failed are returned as not cached along with error.
+	GetSeries(context.Context, ...utils.Variant) ([]*proto.ThumbnailResponse, []utils.Variant, error)
+	SetSeries(context.Context, ...*proto.Thumbnail)
+}
+
+// StatsReporter is a Cache that counts lookups of its tiers
+type StatsReporter interface {
+	Stats() []TierStats
+}
+
+// pickCached selects cached entry that satisfies variant.
+// Resolutions available on YouTube are stored with every cached thumbnail,
+// so lower cached resolution isn't served while a higher one exists upstream.
+func pickCached[T any](variant utils.Variant, cached map[proto.Resolution]T,
+	available []proto.Resolution) (T, bool) {
+	var empty T
+
+	res, ok := utils.PickResolution(available, variant.Resolution, variant.Fallback)
+	if !ok {
+		return empty, false
+	}
+
+	entry, hit := cached[res]
+	return entry, hit
+}
@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+	"golang.org/x/exp/slog"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+var _ Cache = (*MemoryCache)(nil)
+
+// MemoryCache is an in-process LRU cache of thumbnails.
+// It keeps meta data and image bytes, so it doesn't need Redis or filesystem.
+// Zero limit means no limit.
+type MemoryCache struct {
+	mu sync.Mutex
+
+	maxEntries int
+	maxBytes   int64
+	size       int64
+	entries    map[string]*list.Element
+	order      *list.List // front is the most recently used
+
+	// onEvict is called for every thumbnail evicted over limits
+	onEvict func(*proto.Thumbnail)
+
+	// maxAge expires thumbnails by Thumbnail.FetchedAt. Zero means no expiration.
+	maxAge time.Duration
+}
+
+func NewMemoryCache(maxEntries int, maxBytes int64) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// OnEvict sets callback called for every evicted thumbnail
+func (c *MemoryCache) OnEvict(f func(*proto.Thumbnail)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onEvict = f
+}
+
+// SetMaxAge sets expiration of thumbnails
+func (c *MemoryCache) SetMaxAge(maxAge time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxAge = maxAge
+}
+
+// expired reports that thumbnail is older than maxAge
+func (c *MemoryCache) expired(thumb *proto.Thumbnail) bool {
+	return c.maxAge > 0 && time.Since(time.Unix(thumb.GetFetchedAt(), 0)) > c.maxAge
+}
+
+// Len returns number of cached thumbnails
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Size returns approximate number of cached bytes
+func (c *MemoryCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// entrySize is an approximate memory usage of thumbnail
+func entrySize(thumb *proto.Thumbnail) int64 {
+	return int64(len(thumb.GetFile()) + len(thumb.GetId()) + len(thumb.GetUrl()) +
+		len(thumb.GetTitle()) + len(thumb.GetChannelTitle()) + len(thumb.GetMimeType()) +
+		protobuf.Size(thumb.GetMetadata()))
+}
+
+// lookup finds thumbnail that satisfies variant and marks it as recently used
+func (c *MemoryCache) lookup(variant utils.Variant) *proto.Thumbnail {
+	var (
+		cached    = make(map[proto.Resolution]*list.Element)
+		available []proto.Resolution
+	)
+
+	for _, res := range utils.ResolutionCandidates(variant.Resolution, variant.Fallback) {
+		elem, ok := c.entries[utils.VariantKey(variant.ID, res)]
+		if !ok || c.expired(elem.Value.(*proto.Thumbnail)) {
+			continue
+		}
+		cached[res] = elem
+		if available == nil {
+			available = elem.Value.(*proto.Thumbnail).GetAvailable()
+		}
+	}
+
+	elem, hit := pickCached(variant, cached, available)
+	if !hit || !variant.Covers(elem.Value.(*proto.Thumbnail)) {
+		return nil
+	}
+
+	c.order.MoveToFront(elem)
+	return elem.Value.(*proto.Thumbnail)
+}
+
+func newCachedResponse(thumb *proto.Thumbnail) *proto.ThumbnailResponse {
+	return &proto.ThumbnailResponse{
+		Content: &proto.ThumbnailResponse_Thumbnail{
+			Thumbnail: thumb,
+		},
+	}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, variant utils.Variant) (*proto.ThumbnailResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if thumb := c.lookup(variant); thumb != nil {
+		slog.Debug("Searching in memory cache",
+			fmt.Sprintf("%s in cache", utils.ThumbnailKey(thumb)),
+		)
+		return newCachedResponse(thumb), nil
+	}
+	return nil, nil
+}
+
+func (c *MemoryCache) GetSeries(ctx context.Context, poolVariant ...utils.Variant) (
+	[]*proto.ThumbnailResponse, []utils.Variant, error) {
+	var (
+		thumbnailPool []*proto.ThumbnailResponse
+		notInCache    []utils.Variant = nil
+	)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, variant := range poolVariant {
+		if thumb := c.lookup(variant); thumb != nil {
+			thumbnailPool = append(thumbnailPool, newCachedResponse(thumb))
+		} else {
+			notInCache = append(notInCache, variant)
+		}
+	}
+
+	slog.Debug("Searching in memory cache",
+		fmt.Sprintf("at cache: %d; new: %d;", len(thumbnailPool), len(notInCache)),
+	)
+
+	return thumbnailPool, notInCache, nil
+}
+
+// keepModified returns video with modification time of cached thumbnail if image is the same.
+// Video is cloned, because it may be still sent to client.
+func keepModified(video, cached *proto.Thumbnail) *proto.Thumbnail {
+	if video.GetEtag() == "" || video.GetEtag() != cached.GetEtag() ||
+		video.GetLastModified() == cached.GetLastModified() {
+		return video
+	}
+
+	kept := protobuf.Clone(video).(*proto.Thumbnail)
+	kept.LastModified = cached.GetLastModified()
+	return kept
+}
+
+// SetSeries stores thumbnails and evicts the least recently used ones over limits
+func (c *MemoryCache) SetSeries(ctx context.Context, poolVideo ...*proto.Thumbnail) {
+	var evicted []*proto.Thumbnail
+
+	c.mu.Lock()
+	for _, video := range poolVideo {
+		key := utils.ThumbnailKey(video)
+		if elem, ok := c.entries[key]; ok {
+			cached := elem.Value.(*proto.Thumbnail)
+			c.size -= entrySize(cached)
+			elem.Value = keepModified(video, cached)
+			c.order.MoveToFront(elem)
+		} else {
+			c.entries[key] = c.order.PushFront(video)
+		}
+		c.size += entrySize(video)
+	}
+
+	for c.order.Len() > 0 && c.overLimit() {
+		oldest := c.order.Back()
+		thumb := oldest.Value.(*proto.Thumbnail)
+
+		c.order.Remove(oldest)
+		delete(c.entries, utils.ThumbnailKey(thumb))
+		c.size -= entrySize(thumb)
+		evicted = append(evicted, thumb)
+	}
+	onEvict := c.onEvict
+	c.mu.Unlock()
+
+	if onEvict != nil {
+		for _, thumb := range evicted {
+			onEvict(thumb)
+		}
+	}
+}
+
+func (c *MemoryCache) overLimit() bool {
+	return (c.maxEntries > 0 && c.order.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && c.size > c.maxBytes)
+}
@@ -3,32 +3,35 @@ package cache
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
 	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
 	"github.com/go-redis/redis"
 	"golang.org/x/exp/slog"
+	protobuf "google.golang.org/protobuf/proto"
 )
 
 const (
-	baseKey = "video:"
-	curDir  = "/libs/cache"
+	baseKey   = "video:"
+	refsKey   = "blob:" // set of video hashes referencing blob
+	blobField = "blob"  // digest of image in video hash
 
-	ErrClosed = "redis: client is closed"
+	modifiedField = "modified_at" // unix time when image of video hash changed
+	metadataField = "metadata"    // VideoMetadata in protobuf encoding
+	curDir        = "/libs/cache"
 )
 
-type Cache interface {
-	Get(context.Context, string) *proto.ThumbnailResponse
-	GetSeries(context.Context, ...string) ([]*proto.ThumbnailResponse, []string)
-	SetSeries(context.Context, ...*proto.Thumbnail)
-}
-
 var _ Cache = (*RedisQuery)(nil)
 
 type RedisQuery struct {
 	Redis *redis.Client
 
 	Context context.Context
+
+	// TTL is an expiration of video hashes. Zero means no expiration.
+	TTL time.Duration
 }
 
 func NewRedisQuery(ctx context.Context, client *redis.Client) *RedisQuery {
@@ -38,55 +41,112 @@ func NewRedisQuery(ctx context.Context, client *redis.Client) *RedisQuery {
 	}
 }
 
-func (q *RedisQuery) Get(ctx context.Context, videoID string) *proto.ThumbnailResponse {
+// refKey returns redis set key of blob references
+func refKey(digest string) string {
+	return refsKey + digest
+}
+
+// getKey returns redis hash key of thumbnail in resolution
+func getKey(videoID string, res proto.Resolution) string {
+	return baseKey + utils.VariantKey(videoID, res)
+}
+
+// queueVariant adds HGETALL of every resolution candidate of variant to pipeline
+func queueVariant(pipeline redis.Pipeliner, variant utils.Variant) []*redis.StringStringMapCmd {
+	var (
+		candidates = utils.ResolutionCandidates(variant.Resolution, variant.Fallback)
+		cmds       = make([]*redis.StringStringMapCmd, 0, len(candidates))
+	)
+
+	for _, res := range candidates {
+		cmds = append(cmds, pipeline.HGetAll(getKey(variant.ID, res)))
+	}
+	return cmds
+}
+
+// resolveVariant picks cached response that satisfies variant.
+// Image isn't read for metadata only variant and for client that already has it.
+func resolveVariant(variant utils.Variant, cmds []*redis.StringStringMapCmd) *proto.ThumbnailResponse {
 	var (
-		hash = baseKey + videoID
-		exec = q.Redis.HGetAll(hash)
+		cached    = make(map[proto.Resolution]*redis.StringStringMapCmd, len(cmds))
+		available []proto.Resolution
 	)
 
-	if exec.Err() == nil {
-		resp := utils.NewThumbnailResponse(exec)
-		if resp != nil && resp.GetThumbnail().GetId() == videoID {
-			slog.Debug("Searching in redis cache",
-				fmt.Sprintf("%s in cache", resp.GetThumbnail().GetId()),
-			)
-			return resp
+	for _, cmd := range cmds {
+		values := cmd.Val()
+		if cmd.Err() != nil || values["id"] != variant.ID {
+			continue
+		}
+
+		res, ok := utils.ParseResolution(values["resolution"])
+		if !ok {
+			continue
+		}
+		cached[res] = cmd
+		if available == nil {
+			available = utils.SplitResolutions(values["available"])
 		}
 	}
 
-	return nil
+	cmd, hit := pickCached(variant, cached, available)
+	if !hit {
+		return nil
+	}
+
+	// Entry without requested meta data parts is a cache miss
+	resp := utils.NewCachedMetadataResponse(cmd)
+	if resp == nil || !variant.Covers(resp.GetThumbnail()) {
+		return nil
+	}
+	if variant.MetadataOnly || variant.Matches(variant.Etag(resp.GetThumbnail().GetEtag())) {
+		return resp
+	}
+	return utils.NewThumbnailResponse(cmd)
 }
 
-func (q *RedisQuery) GetSeries(ctx context.Context, poolVideoID ...string) ([]*proto.ThumbnailResponse, []string) {
+func (q *RedisQuery) Get(ctx context.Context, variant utils.Variant) (*proto.ThumbnailResponse, error) {
+	pipeline := q.Redis.Pipeline()
+	cmds := queueVariant(pipeline, variant)
+
+	// HGETALL of missing key isn't an error, so any error is a failure of redis
+	if _, err := pipeline.Exec(); err != nil {
+		slog.Error("Redis pipeline execution failed", err, curDir)
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	resp := resolveVariant(variant, cmds)
+	if resp != nil {
+		slog.Debug("Searching in redis cache",
+			fmt.Sprintf("%s in cache", utils.ThumbnailKey(resp.GetThumbnail())),
+		)
+	}
+	return resp, nil
+}
+
+func (q *RedisQuery) GetSeries(ctx context.Context, poolVariant ...utils.Variant) (
+	[]*proto.ThumbnailResponse, []utils.Variant, error) {
 	var (
 		thumbnailPool []*proto.ThumbnailResponse
-		notInCache    []string = nil
-		pipeline               = q.Redis.Pipeline()
+		notInCache    []utils.Variant = nil
+		pipeline                      = q.Redis.Pipeline()
+		executed                      = make([][]*redis.StringStringMapCmd, 0, len(poolVariant))
 	)
 
-	for _, str := range poolVideoID {
-		hash := baseKey + str
-		pipeline.HGetAll(hash)
+	for _, variant := range poolVariant {
+		executed = append(executed, queueVariant(pipeline, variant))
 	}
 
-	executed, err := pipeline.Exec()
-	if err != nil {
-		if err.Error() == ErrClosed {
-			slog.Error("Redis pipeline execution failed", err, curDir)
-			return nil, poolVideoID
-		}
+	if _, err := pipeline.Exec(); err != nil {
+		slog.Error("Redis pipeline execution failed", err, curDir)
+		return nil, poolVariant, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 
-	for index, ex := range executed {
-		if ex.Err() == nil {
-			thumbnail := utils.NewThumbnailResponse(ex.(*redis.StringStringMapCmd))
-			if thumbnail != nil {
-				thumbnailPool = append(thumbnailPool, thumbnail)
-			} else {
-				notInCache = append(notInCache, poolVideoID[index])
-			}
+	for index, cmds := range executed {
+		thumbnail := resolveVariant(poolVariant[index], cmds)
+		if thumbnail != nil {
+			thumbnailPool = append(thumbnailPool, thumbnail)
 		} else {
-			notInCache = append(notInCache, poolVideoID[index])
+			notInCache = append(notInCache, poolVariant[index])
 		}
 	}
 
@@ -94,25 +154,83 @@ func (q *RedisQuery) GetSeries(ctx context.Context, poolVideoID ...string) ([]*p
 		fmt.Sprintf("at cache: %d; new: %d;", len(thumbnailPool), len(notInCache)),
 	)
 
-	return thumbnailPool, notInCache
+	return thumbnailPool, notInCache, nil
 }
 
 func (q *RedisQuery) Set(ctx context.Context, video *proto.Thumbnail) {
 	// Unused
 }
 
+// SetSeries provides video meta data to redis
+// and thumbnail image to blob store.
+// Every blob keeps set of video hashes referencing it.
 func (q *RedisQuery) SetSeries(ctx context.Context, poolVideo ...*proto.Thumbnail) {
+	// Blobs referenced before overwriting are released.
+	// Modification time is kept while image content is the same.
+	var (
+		readPipeline = q.Redis.Pipeline()
+		previous     = make([]*redis.SliceCmd, len(poolVideo))
+	)
+	for index, video := range poolVideo {
+		previous[index] = readPipeline.HMGet(getKey(video.GetId(), video.GetResolution()),
+			blobField, modifiedField)
+	}
+	if _, err := readPipeline.Exec(); err != nil && err != redis.Nil {
+		slog.Warn("Blob references reading failed", err, curDir)
+	}
+
 	pipeline := q.Redis.Pipeline()
-	for _, video := range poolVideo {
-		hash := baseKey + video.GetId()
-		pipeline.HMSet(hash, map[string]any{
+	for index, video := range poolVideo {
+		digest, err := blobstore.Media.Put(video.GetFile())
+		if err != nil {
+			slog.Warn("Writing image file denied", err, curDir)
+			continue
+		}
+
+		hash := getKey(video.GetId(), video.GetResolution())
+		fields := map[string]any{
 			"id":           video.GetId(),
+			"resolution":   utils.ResolutionName(video.GetResolution()),
+			"available":    utils.JoinResolutions(video.GetAvailable()...),
 			"url":          video.GetUrl(),
 			"channelTitle": video.GetChannelTitle(),
 			"title":        video.GetTitle(),
 			"width":        video.GetWidth(),
 			"height":       video.GetHeight(),
-		})
+			"fetched_at":   video.GetFetchedAt(),
+			blobField:      digest,
+		}
+		if meta := video.GetMetadata(); meta != nil {
+			if data, err := protobuf.Marshal(meta); err == nil {
+				fields[metadataField] = data
+			}
+		}
+
+		var (
+			old   string
+			known bool
+		)
+		if values := previous[index].Val(); len(values) == 2 {
+			old, _ = values[0].(string)
+			_, known = values[1].(string)
+		}
+		if old != digest || !known {
+			modifiedAt := video.GetLastModified()
+			if modifiedAt == 0 {
+				modifiedAt = video.GetFetchedAt()
+			}
+			fields[modifiedField] = modifiedAt
+		}
+
+		pipeline.HMSet(hash, fields)
+		if q.TTL > 0 {
+			pipeline.Expire(hash, q.TTL)
+		}
+
+		if old != "" && old != digest {
+			pipeline.SRem(refKey(old), hash)
+		}
+		pipeline.SAdd(refKey(digest), hash)
 	}
 	_, err := pipeline.Exec()
 	if err != nil {
@@ -121,3 +239,106 @@ func (q *RedisQuery) SetSeries(ctx context.Context, poolVideo ...*proto.Thumbnai
 		slog.Debug("Set pipeline execution succesful", curDir)
 	}
 }
+
+// ForgetMedia removes video hashes referencing evicted blobs.
+// It is an eviction callback of blobstore.Sweeper.
+func (q *RedisQuery) ForgetMedia(digests ...string) {
+	if len(digests) == 0 {
+		return
+	}
+
+	pipeline := q.Redis.Pipeline()
+	members := make([]*redis.StringSliceCmd, len(digests))
+	for index, digest := range digests {
+		members[index] = pipeline.SMembers(refKey(digest))
+	}
+	if _, err := pipeline.Exec(); err != nil {
+		slog.Warn("Blob references reading failed", err, curDir)
+		return
+	}
+
+	for index, digest := range digests {
+		for _, hash := range members[index].Val() {
+			pipeline.Del(hash)
+		}
+		pipeline.Del(refKey(digest))
+	}
+
+	if _, err := pipeline.Exec(); err != nil {
+		slog.Warn("Forget media pipeline execution failed", err, curDir)
+	} else {
+		slog.Debug("Evicted media forgotten", len(digests), curDir)
+	}
+}
+
+// CollectGarbage removes blobs which aren't referenced by any video hash
+// and aren't accessed longer than grace. It returns digests of removed blobs.
+func (q *RedisQuery) CollectGarbage(grace time.Duration) []string {
+	return blobstore.Media.Collect(grace, q.unreferenced)
+}
+
+// CollectingGarbage collects garbage periodically.
+// Can be shutted down by context Cancelation.
+func (q *RedisQuery) CollectingGarbage(ctx context.Context, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			q.CollectGarbage(grace)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// unreferenced reconciles reference sets of blobs with video hashes
+// and returns digests of blobs without references.
+// Expired and overwritten hashes are removed from reference sets.
+func (q *RedisQuery) unreferenced(digests ...string) []string {
+	pipeline := q.Redis.Pipeline()
+	members := make([]*redis.StringSliceCmd, len(digests))
+	for index, digest := range digests {
+		members[index] = pipeline.SMembers(refKey(digest))
+	}
+	if _, err := pipeline.Exec(); err != nil {
+		slog.Warn("Blob references reading failed", err, curDir)
+		return nil
+	}
+
+	// Blob currently referenced by every hash of reference set
+	blobs := make([][]*redis.StringCmd, len(digests))
+	for index, cmd := range members {
+		for _, hash := range cmd.Val() {
+			blobs[index] = append(blobs[index], pipeline.HGet(hash, blobField))
+		}
+	}
+	if _, err := pipeline.Exec(); err != nil && err != redis.Nil {
+		slog.Warn("Video hashes reading failed", err, curDir)
+		return nil
+	}
+
+	var unreferenced []string
+	for index, digest := range digests {
+		live := 0
+		for i, cmd := range blobs[index] {
+			if cmd.Val() == digest {
+				live++
+				continue
+			}
+			pipeline.SRem(refKey(digest), members[index].Val()[i])
+		}
+
+		if live == 0 {
+			pipeline.Del(refKey(digest))
+			unreferenced = append(unreferenced, digest)
+		}
+	}
+
+	if _, err := pipeline.Exec(); err != nil {
+		slog.Warn("Blob references cleanup failed", err, curDir)
+		return nil
+	}
+	return unreferenced
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+	"golang.org/x/exp/slog"
+)
+
+var _ Cache = (*TieredCache)(nil)
+
+// TierStats is a lookup statistic of cache tier
+type TierStats struct {
+	Tier   string
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRatio is a share of lookups served by tier
+func (s TierStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+func (s TierStats) String() string {
+	return fmt.Sprintf("%s: hits %d; misses %d; ratio %.2f;", s.Tier, s.Hits, s.Misses, s.HitRatio())
+}
+
+type tierCounter struct {
+	hits, misses atomic.Uint64
+}
+
+func (c *tierCounter) add(hits, misses int) {
+	c.hits.Add(uint64(hits))
+	c.misses.Add(uint64(misses))
+}
+
+func (c *tierCounter) stats(tier string) TierStats {
+	return TierStats{Tier: tier, Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// TieredCache is a byte-budgeted in-process hot tier in front of cold Cache
+// (Redis and filesystem). Cold hits are promoted to hot tier,
+// hot tier evictions are demoted to cold tier only.
+// Writes go through both tiers.
+type TieredCache struct {
+	hot  *MemoryCache
+	cold Cache
+
+	hotCounter, coldCounter tierCounter
+	demoted                 atomic.Uint64
+}
+
+func NewTieredCache(hot *MemoryCache, cold Cache) *TieredCache {
+	c := &TieredCache{
+		hot:  hot,
+		cold: cold,
+	}
+
+	// Cold tier keeps every thumbnail written through,
+	// so demotion is a removal from hot tier.
+	hot.OnEvict(func(*proto.Thumbnail) {
+		c.demoted.Add(1)
+	})
+
+	return c
+}
+
+// Stats returns lookup statistic of hot and cold tiers
+func (c *TieredCache) Stats() []TierStats {
+	return []TierStats{
+		c.hotCounter.stats("hot"),
+		c.coldCounter.stats("cold"),
+	}
+}
+
+// Demoted returns number of thumbnails demoted from hot tier
+func (c *TieredCache) Demoted() uint64 {
+	return c.demoted.Load()
+}
+
+// promote copies cold hits to hot tier.
+// Meta data without image isn't promoted, hot tier keeps whole thumbnails.
+func (c *TieredCache) promote(ctx context.Context, responses ...*proto.ThumbnailResponse) {
+	thumbnails := make([]*proto.Thumbnail, 0, len(responses))
+	for _, resp := range responses {
+		if thumb := resp.GetThumbnail(); thumb != nil && len(thumb.GetFile()) != 0 {
+			thumbnails = append(thumbnails, thumb)
+		}
+	}
+	c.hot.SetSeries(ctx, thumbnails...)
+}
+
+func (c *TieredCache) Get(ctx context.Context, variant utils.Variant) (*proto.ThumbnailResponse, error) {
+	if resp, _ := c.hot.Get(ctx, variant); resp != nil {
+		c.hotCounter.add(1, 0)
+		return resp, nil
+	}
+	c.hotCounter.add(0, 1)
+
+	resp, err := c.cold.Get(ctx, variant)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		c.coldCounter.add(0, 1)
+		return nil, nil
+	}
+	c.coldCounter.add(1, 0)
+
+	c.promote(ctx, resp)
+	return resp, nil
+}
+
+// GetSeries returns hot hits along with error of cold tier
+func (c *TieredCache) GetSeries(ctx context.Context, poolVariant ...utils.Variant) (
+	[]*proto.ThumbnailResponse, []utils.Variant, error) {
+	hotThumbnails, coldList, _ := c.hot.GetSeries(ctx, poolVariant...)
+	c.hotCounter.add(len(hotThumbnails), len(coldList))
+	if len(coldList) == 0 {
+		return hotThumbnails, nil, nil
+	}
+
+	coldThumbnails, notInCache, err := c.cold.GetSeries(ctx, coldList...)
+	if err != nil {
+		return hotThumbnails, coldList, err
+	}
+	c.coldCounter.add(len(coldThumbnails), len(notInCache))
+
+	c.promote(ctx, coldThumbnails...)
+
+	slog.Debug("Searching in tiered cache",
+		fmt.Sprintf("hot: %d; cold: %d; new: %d;", len(hotThumbnails), len(coldThumbnails), len(notInCache)),
+	)
+
+	return append(hotThumbnails, coldThumbnails...), notInCache, nil
+}
+
+func (c *TieredCache) SetSeries(ctx context.Context, poolVideo ...*proto.Thumbnail) {
+	c.cold.SetSeries(ctx, poolVideo...)
+	c.hot.SetSeries(ctx, poolVideo...)
+}
@@ -0,0 +1,290 @@
+package gateway
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/internal/routing"
+	"golang.org/x/exp/slog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+const (
+	ThumbnailPath = "/v1/thumbnails"
+	BatchPath     = "/v1/thumbnails:batch"
+
+	maxBatchBody = 1 << 20
+
+	ErrInvalidQuery = "Invalid query parameter"
+	ErrInvalidBody  = "Invalid request body"
+)
+
+var (
+	curDir = "/internal/gateway"
+
+	marshaler   = protojson.MarshalOptions{UseProtoNames: true}
+	unmarshaler = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// statusByCode is an HTTP status of ErrorCode
+var statusByCode = map[proto.ErrorCode]int{
+	proto.ErrorCode_INVALID_URL:      http.StatusBadRequest,
+	proto.ErrorCode_INVALID_REQUEST:  http.StatusBadRequest,
+	proto.ErrorCode_NOT_FOUND:        http.StatusNotFound,
+	proto.ErrorCode_PRIVATE:          http.StatusForbidden,
+	proto.ErrorCode_QUOTA_EXCEEDED:   http.StatusTooManyRequests,
+	proto.ErrorCode_UPSTREAM_TIMEOUT: http.StatusGatewayTimeout,
+	proto.ErrorCode_UPSTREAM_ERROR:   http.StatusBadGateway,
+	proto.ErrorCode_CACHE_ERROR:      http.StatusServiceUnavailable,
+}
+
+// Gateway is an HTTP/JSON front end of ThumbnailFetcher
+type Gateway struct {
+	fetcher routing.ThumbnailFetcher
+
+	// ttl is a freshness of thumbnail used by Cache-Control
+	ttl time.Duration
+}
+
+func NewGateway(fetcher routing.ThumbnailFetcher, ttl time.Duration) *Gateway {
+	return &Gateway{
+		fetcher: fetcher,
+		ttl:     ttl,
+	}
+}
+
+// Register adds routes of gateway to mux
+func (g *Gateway) Register(mux *http.ServeMux) {
+	mux.HandleFunc(ThumbnailPath, g.getThumbnail)
+	mux.HandleFunc(BatchPath, g.batchThumbnails)
+}
+
+func writeJSON(w http.ResponseWriter, code int, msg protobuf.Message) {
+	data, err := marshaler.Marshal(msg)
+	if err != nil {
+		slog.Error("JSON marshaling failed", err, curDir)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, errResp *proto.ErrorResponse) {
+	code, ok := statusByCode[errResp.GetCode()]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+	writeJSON(w, code, errResp)
+}
+
+// writeStatusError writes RPC-level error of fetcher
+func writeStatusError(w http.ResponseWriter, url string, err error) {
+	st := status.Convert(err)
+	errResp := &proto.ErrorResponse{
+		Url:          url,
+		ErrorMessage: st.Message(),
+		Code:         proto.ErrorCode_UPSTREAM_ERROR,
+	}
+	if st.Code() == codes.InvalidArgument {
+		errResp.Code = proto.ErrorCode_INVALID_REQUEST
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			errResp.Code = proto.ErrorCode(proto.ErrorCode_value[info.GetReason()])
+		}
+	}
+	writeError(w, errResp)
+}
+
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	return false
+}
+
+// parseEnum parses enum value by name, case insensitive
+func parseEnum(values map[string]int32, name string) (int32, error) {
+	if name == "" {
+		return 0, nil
+	}
+	value, ok := values[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown value %q", name)
+	}
+	return value, nil
+}
+
+func parseSize(src string) (int32, error) {
+	if src == "" {
+		return 0, nil
+	}
+	size, err := strconv.ParseInt(src, 10, 32)
+	return int32(size), err
+}
+
+// parseThumbnailQuery builds GetThumbnailRequest from query parameters
+func parseThumbnailQuery(r *http.Request) (*proto.GetThumbnailRequest, error) {
+	var (
+		query = r.URL.Query()
+		req   = &proto.GetThumbnailRequest{Url: query.Get("url")}
+	)
+
+	if req.Url == "" {
+		return nil, fmt.Errorf("url: required")
+	}
+
+	enums := []struct {
+		param  string
+		values map[string]int32
+		set    func(int32)
+	}{
+		{"resolution", proto.Resolution_value, func(v int32) { req.Resolution = proto.Resolution(v) }},
+		{"fallback", proto.FallbackPolicy_value, func(v int32) { req.Fallback = proto.FallbackPolicy(v) }},
+		{"fit", proto.FitMode_value, func(v int32) { req.Fit = proto.FitMode(v) }},
+		{"format", proto.ImageFormat_value, func(v int32) { req.Format = proto.ImageFormat(v) }},
+	}
+	for _, enum := range enums {
+		value, err := parseEnum(enum.values, query.Get(enum.param))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", enum.param, err)
+		}
+		enum.set(value)
+	}
+
+	var err error
+	if req.Width, err = parseSize(query.Get("width")); err != nil {
+		return nil, fmt.Errorf("width: %w", err)
+	}
+	if req.Height, err = parseSize(query.Get("height")); err != nil {
+		return nil, fmt.Errorf("height: %w", err)
+	}
+	if src := query.Get("include_file"); src != "" {
+		includeFile, err := strconv.ParseBool(src)
+		if err != nil {
+			return nil, fmt.Errorf("include_file: %w", err)
+		}
+		req.IncludeFile = &includeFile
+	}
+	if src := query.Get("parts"); src != "" {
+		for _, name := range strings.Split(src, ",") {
+			part, err := parseEnum(proto.MetadataPart_value, strings.TrimSpace(name))
+			if err != nil {
+				return nil, fmt.Errorf("parts: %w", err)
+			}
+			req.Parts = append(req.Parts, proto.MetadataPart(part))
+		}
+	}
+	return req, nil
+}
+
+// cacheControl tells how long thumbnail stays fresh
+func (g *Gateway) cacheControl(thumb *proto.Thumbnail) string {
+	if g.ttl <= 0 {
+		return "public, max-age=86400"
+	}
+
+	age := time.Since(time.Unix(thumb.GetFetchedAt(), 0))
+	maxAge := int((g.ttl - age).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	return fmt.Sprintf("public, max-age=%d", maxAge)
+}
+
+// getThumbnail returns image of GET /v1/thumbnails?url=...
+// ThumbnailResponse JSON is returned instead if include_file=false.
+func (g *Gateway) getThumbnail(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	req, err := parseThumbnailQuery(r)
+	if err != nil {
+		writeError(w, &proto.ErrorResponse{
+			Url:          r.URL.Query().Get("url"),
+			ErrorMessage: ErrInvalidQuery + ": " + err.Error(),
+			Code:         proto.ErrorCode_INVALID_REQUEST,
+		})
+		return
+	}
+
+	req.IfNoneMatch = r.Header.Get("If-None-Match")
+
+	resp, err := g.fetcher.FetchThumbnail(r.Context(), req)
+	if err != nil {
+		writeStatusError(w, req.GetUrl(), err)
+		return
+	}
+	if errResp := resp.GetError(); errResp != nil {
+		writeError(w, errResp)
+		return
+	}
+
+	thumb := resp.GetThumbnail()
+	w.Header().Set("Cache-Control", g.cacheControl(thumb))
+	if req.IncludeFile != nil && !req.GetIncludeFile() {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	w.Header().Set("ETag", `"`+thumb.GetEtag()+`"`)
+	w.Header().Set("X-Thumbnail-Resolution", thumb.GetResolution().String())
+	if thumb.GetNotModified() {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", thumb.GetMimeType())
+	http.ServeContent(w, r, "", time.Unix(thumb.GetLastModified(), 0), bytes.NewReader(thumb.GetFile()))
+}
+
+// batchThumbnails returns ListThumbnailResponse JSON of POST /v1/thumbnails:batch
+func (g *Gateway) batchThumbnails(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBody))
+	if err != nil {
+		writeError(w, &proto.ErrorResponse{
+			ErrorMessage: ErrInvalidBody + ": " + err.Error(),
+			Code:         proto.ErrorCode_INVALID_REQUEST,
+		})
+		return
+	}
+
+	var reqList proto.ListThumbnailRequest
+	if err := unmarshaler.Unmarshal(body, &reqList); err != nil {
+		writeError(w, &proto.ErrorResponse{
+			ErrorMessage: ErrInvalidBody + ": " + err.Error(),
+			Code:         proto.ErrorCode_INVALID_REQUEST,
+		})
+		return
+	}
+
+	resp, err := g.fetcher.FetchThumbnailList(r.Context(), &reqList)
+	if err != nil {
+		writeStatusError(w, "", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &proto.ListThumbnailResponse{Thumbnails: resp})
+}
@@ -20,12 +20,389 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Resolution int32
+
+const (
+	Resolution_MAXRES   Resolution = 0
+	Resolution_STANDARD Resolution = 1
+	Resolution_HIGH     Resolution = 2
+	Resolution_MEDIUM   Resolution = 3
+	Resolution_DEFAULT  Resolution = 4
+)
+
+// Enum value maps for Resolution.
+var (
+	Resolution_name = map[int32]string{
+		0: "MAXRES",
+		1: "STANDARD",
+		2: "HIGH",
+		3: "MEDIUM",
+		4: "DEFAULT",
+	}
+	Resolution_value = map[string]int32{
+		"MAXRES":   0,
+		"STANDARD": 1,
+		"HIGH":     2,
+		"MEDIUM":   3,
+		"DEFAULT":  4,
+	}
+)
+
+func (x Resolution) Enum() *Resolution {
+	p := new(Resolution)
+	*p = x
+	return p
+}
+
+func (x Resolution) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Resolution) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_thumbnails_proto_enumTypes[0].Descriptor()
+}
+
+func (Resolution) Type() protoreflect.EnumType {
+	return &file_api_thumbnails_proto_enumTypes[0]
+}
+
+func (x Resolution) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Resolution.Descriptor instead.
+func (Resolution) EnumDescriptor() ([]byte, []int) {
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{0}
+}
+
+type FallbackPolicy int32
+
+const (
+	FallbackPolicy_FALLBACK_LOWER  FallbackPolicy = 0
+	FallbackPolicy_FALLBACK_HIGHER FallbackPolicy = 1
+	FallbackPolicy_FALLBACK_NONE   FallbackPolicy = 2
+)
+
+// Enum value maps for FallbackPolicy.
+var (
+	FallbackPolicy_name = map[int32]string{
+		0: "FALLBACK_LOWER",
+		1: "FALLBACK_HIGHER",
+		2: "FALLBACK_NONE",
+	}
+	FallbackPolicy_value = map[string]int32{
+		"FALLBACK_LOWER":  0,
+		"FALLBACK_HIGHER": 1,
+		"FALLBACK_NONE":   2,
+	}
+)
+
+func (x FallbackPolicy) Enum() *FallbackPolicy {
+	p := new(FallbackPolicy)
+	*p = x
+	return p
+}
+
+func (x FallbackPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FallbackPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_thumbnails_proto_enumTypes[1].Descriptor()
+}
+
+func (FallbackPolicy) Type() protoreflect.EnumType {
+	return &file_api_thumbnails_proto_enumTypes[1]
+}
+
+func (x FallbackPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FallbackPolicy.Descriptor instead.
+func (FallbackPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{1}
+}
+
+type FitMode int32
+
+const (
+	FitMode_FIT_CONTAIN FitMode = 0
+	FitMode_FIT_COVER   FitMode = 1
+	FitMode_FIT_EXACT   FitMode = 2
+)
+
+// Enum value maps for FitMode.
+var (
+	FitMode_name = map[int32]string{
+		0: "FIT_CONTAIN",
+		1: "FIT_COVER",
+		2: "FIT_EXACT",
+	}
+	FitMode_value = map[string]int32{
+		"FIT_CONTAIN": 0,
+		"FIT_COVER":   1,
+		"FIT_EXACT":   2,
+	}
+)
+
+func (x FitMode) Enum() *FitMode {
+	p := new(FitMode)
+	*p = x
+	return p
+}
+
+func (x FitMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FitMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_thumbnails_proto_enumTypes[2].Descriptor()
+}
+
+func (FitMode) Type() protoreflect.EnumType {
+	return &file_api_thumbnails_proto_enumTypes[2]
+}
+
+func (x FitMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FitMode.Descriptor instead.
+func (FitMode) EnumDescriptor() ([]byte, []int) {
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{2}
+}
+
+type ImageFormat int32
+
+const (
+	ImageFormat_ORIGINAL ImageFormat = 0
+	ImageFormat_JPEG     ImageFormat = 1
+	ImageFormat_PNG      ImageFormat = 2
+	ImageFormat_WEBP     ImageFormat = 3
+)
+
+// Enum value maps for ImageFormat.
+var (
+	ImageFormat_name = map[int32]string{
+		0: "ORIGINAL",
+		1: "JPEG",
+		2: "PNG",
+		3: "WEBP",
+	}
+	ImageFormat_value = map[string]int32{
+		"ORIGINAL": 0,
+		"JPEG":     1,
+		"PNG":      2,
+		"WEBP":     3,
+	}
+)
+
+func (x ImageFormat) Enum() *ImageFormat {
+	p := new(ImageFormat)
+	*p = x
+	return p
+}
+
+func (x ImageFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImageFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_thumbnails_proto_enumTypes[3].Descriptor()
+}
+
+func (ImageFormat) Type() protoreflect.EnumType {
+	return &file_api_thumbnails_proto_enumTypes[3]
+}
+
+func (x ImageFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImageFormat.Descriptor instead.
+func (ImageFormat) EnumDescriptor() ([]byte, []int) {
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{3}
+}
+
+type Delivery int32
+
+const (
+	Delivery_INLINE     Delivery = 0
+	Delivery_SIGNED_URL Delivery = 1
+)
+
+// Enum value maps for Delivery.
+var (
+	Delivery_name = map[int32]string{
+		0: "INLINE",
+		1: "SIGNED_URL",
+	}
+	Delivery_value = map[string]int32{
+		"INLINE":     0,
+		"SIGNED_URL": 1,
+	}
+)
+
+func (x Delivery) Enum() *Delivery {
+	p := new(Delivery)
+	*p = x
+	return p
+}
+
+func (x Delivery) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Delivery) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_thumbnails_proto_enumTypes[4].Descriptor()
+}
+
+func (Delivery) Type() protoreflect.EnumType {
+	return &file_api_thumbnails_proto_enumTypes[4]
+}
+
+func (x Delivery) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Delivery.Descriptor instead.
+func (Delivery) EnumDescriptor() ([]byte, []int) {
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{4}
+}
+
+type MetadataPart int32
+
+const (
+	MetadataPart_SNIPPET         MetadataPart = 0
+	MetadataPart_CONTENT_DETAILS MetadataPart = 1
+	MetadataPart_STATISTICS      MetadataPart = 2
+)
+
+// Enum value maps for MetadataPart.
+var (
+	MetadataPart_name = map[int32]string{
+		0: "SNIPPET",
+		1: "CONTENT_DETAILS",
+		2: "STATISTICS",
+	}
+	MetadataPart_value = map[string]int32{
+		"SNIPPET":         0,
+		"CONTENT_DETAILS": 1,
+		"STATISTICS":      2,
+	}
+)
+
+func (x MetadataPart) Enum() *MetadataPart {
+	p := new(MetadataPart)
+	*p = x
+	return p
+}
+
+func (x MetadataPart) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MetadataPart) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_thumbnails_proto_enumTypes[5].Descriptor()
+}
+
+func (MetadataPart) Type() protoreflect.EnumType {
+	return &file_api_thumbnails_proto_enumTypes[5]
+}
+
+func (x MetadataPart) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MetadataPart.Descriptor instead.
+func (MetadataPart) EnumDescriptor() ([]byte, []int) {
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{5}
+}
+
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_UNSPECIFIED ErrorCode = 0
+	ErrorCode_INVALID_URL       ErrorCode = 1
+	ErrorCode_NOT_FOUND         ErrorCode = 2
+	ErrorCode_PRIVATE           ErrorCode = 3
+	ErrorCode_QUOTA_EXCEEDED    ErrorCode = 4
+	ErrorCode_UPSTREAM_TIMEOUT  ErrorCode = 5
+	ErrorCode_UPSTREAM_ERROR    ErrorCode = 6
+	ErrorCode_CACHE_ERROR       ErrorCode = 7
+	ErrorCode_INVALID_REQUEST   ErrorCode = 8
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0: "ERROR_UNSPECIFIED",
+		1: "INVALID_URL",
+		2: "NOT_FOUND",
+		3: "PRIVATE",
+		4: "QUOTA_EXCEEDED",
+		5: "UPSTREAM_TIMEOUT",
+		6: "UPSTREAM_ERROR",
+		7: "CACHE_ERROR",
+		8: "INVALID_REQUEST",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_UNSPECIFIED": 0,
+		"INVALID_URL":       1,
+		"NOT_FOUND":         2,
+		"PRIVATE":           3,
+		"QUOTA_EXCEEDED":    4,
+		"UPSTREAM_TIMEOUT":  5,
+		"UPSTREAM_ERROR":    6,
+		"CACHE_ERROR":       7,
+		"INVALID_REQUEST":   8,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_thumbnails_proto_enumTypes[6].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_api_thumbnails_proto_enumTypes[6]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{6}
+}
+
 type GetThumbnailRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Url         string         `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Resolution  Resolution     `protobuf:"varint,2,opt,name=resolution,proto3,enum=thumbnails.Resolution" json:"resolution,omitempty"`
+	Fallback    FallbackPolicy `protobuf:"varint,3,opt,name=fallback,proto3,enum=thumbnails.FallbackPolicy" json:"fallback,omitempty"`
+	Width       int32          `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"`
+	Height      int32          `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`
+	Fit         FitMode        `protobuf:"varint,6,opt,name=fit,proto3,enum=thumbnails.FitMode" json:"fit,omitempty"`
+	Format      ImageFormat    `protobuf:"varint,7,opt,name=format,proto3,enum=thumbnails.ImageFormat" json:"format,omitempty"`
+	Delivery    Delivery       `protobuf:"varint,8,opt,name=delivery,proto3,enum=thumbnails.Delivery" json:"delivery,omitempty"`
+	IfNoneMatch string         `protobuf:"bytes,9,opt,name=if_none_match,json=ifNoneMatch,proto3" json:"if_none_match,omitempty"`
+	IncludeFile *bool          `protobuf:"varint,10,opt,name=include_file,json=includeFile,proto3,oneof" json:"include_file,omitempty"`
+	Parts       []MetadataPart `protobuf:"varint,11,rep,packed,name=parts,proto3,enum=thumbnails.MetadataPart" json:"parts,omitempty"`
 }
 
 func (x *GetThumbnailRequest) Reset() {
@@ -67,6 +444,76 @@ func (x *GetThumbnailRequest) GetUrl() string {
 	return ""
 }
 
+func (x *GetThumbnailRequest) GetResolution() Resolution {
+	if x != nil {
+		return x.Resolution
+	}
+	return Resolution_MAXRES
+}
+
+func (x *GetThumbnailRequest) GetFallback() FallbackPolicy {
+	if x != nil {
+		return x.Fallback
+	}
+	return FallbackPolicy_FALLBACK_LOWER
+}
+
+func (x *GetThumbnailRequest) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *GetThumbnailRequest) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *GetThumbnailRequest) GetFit() FitMode {
+	if x != nil {
+		return x.Fit
+	}
+	return FitMode_FIT_CONTAIN
+}
+
+func (x *GetThumbnailRequest) GetFormat() ImageFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ImageFormat_ORIGINAL
+}
+
+func (x *GetThumbnailRequest) GetDelivery() Delivery {
+	if x != nil {
+		return x.Delivery
+	}
+	return Delivery_INLINE
+}
+
+func (x *GetThumbnailRequest) GetIfNoneMatch() string {
+	if x != nil {
+		return x.IfNoneMatch
+	}
+	return ""
+}
+
+func (x *GetThumbnailRequest) GetIncludeFile() bool {
+	if x != nil && x.IncludeFile != nil {
+		return *x.IncludeFile
+	}
+	return false
+}
+
+func (x *GetThumbnailRequest) GetParts() []MetadataPart {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
 type ListThumbnailRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -119,13 +566,23 @@ type Thumbnail struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id           string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Url          string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
-	ChannelTitle string `protobuf:"bytes,3,opt,name=channelTitle,proto3" json:"channelTitle,omitempty"`
-	Title        string `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
-	Width        int32  `protobuf:"varint,5,opt,name=width,proto3" json:"width,omitempty"`
-	Height       int32  `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
-	File         []byte `protobuf:"bytes,7,opt,name=file,proto3" json:"file,omitempty"`
+	Id               string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url              string         `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	ChannelTitle     string         `protobuf:"bytes,3,opt,name=channelTitle,proto3" json:"channelTitle,omitempty"`
+	Title            string         `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Width            int32          `protobuf:"varint,5,opt,name=width,proto3" json:"width,omitempty"`
+	Height           int32          `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	File             []byte         `protobuf:"bytes,7,opt,name=file,proto3" json:"file,omitempty"`
+	Resolution       Resolution     `protobuf:"varint,8,opt,name=resolution,proto3,enum=thumbnails.Resolution" json:"resolution,omitempty"`
+	Available        []Resolution   `protobuf:"varint,9,rep,packed,name=available,proto3,enum=thumbnails.Resolution" json:"available,omitempty"`
+	MimeType         string         `protobuf:"bytes,10,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	FetchedAt        int64          `protobuf:"varint,11,opt,name=fetched_at,json=fetchedAt,proto3" json:"fetched_at,omitempty"`
+	FileUrl          string         `protobuf:"bytes,12,opt,name=file_url,json=fileUrl,proto3" json:"file_url,omitempty"`
+	FileUrlExpiresAt int64          `protobuf:"varint,13,opt,name=file_url_expires_at,json=fileUrlExpiresAt,proto3" json:"file_url_expires_at,omitempty"`
+	Etag             string         `protobuf:"bytes,14,opt,name=etag,proto3" json:"etag,omitempty"`
+	LastModified     int64          `protobuf:"varint,15,opt,name=last_modified,json=lastModified,proto3" json:"last_modified,omitempty"`
+	NotModified      bool           `protobuf:"varint,16,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`
+	Metadata         *VideoMetadata `protobuf:"bytes,17,opt,name=metadata,proto3" json:"metadata,omitempty"`
 }
 
 func (x *Thumbnail) Reset() {
@@ -209,19 +666,217 @@ func (x *Thumbnail) GetFile() []byte {
 	return nil
 }
 
+func (x *Thumbnail) GetResolution() Resolution {
+	if x != nil {
+		return x.Resolution
+	}
+	return Resolution_MAXRES
+}
+
+func (x *Thumbnail) GetAvailable() []Resolution {
+	if x != nil {
+		return x.Available
+	}
+	return nil
+}
+
+func (x *Thumbnail) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *Thumbnail) GetFetchedAt() int64 {
+	if x != nil {
+		return x.FetchedAt
+	}
+	return 0
+}
+
+func (x *Thumbnail) GetFileUrl() string {
+	if x != nil {
+		return x.FileUrl
+	}
+	return ""
+}
+
+func (x *Thumbnail) GetFileUrlExpiresAt() int64 {
+	if x != nil {
+		return x.FileUrlExpiresAt
+	}
+	return 0
+}
+
+func (x *Thumbnail) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *Thumbnail) GetLastModified() int64 {
+	if x != nil {
+		return x.LastModified
+	}
+	return 0
+}
+
+func (x *Thumbnail) GetNotModified() bool {
+	if x != nil {
+		return x.NotModified
+	}
+	return false
+}
+
+func (x *Thumbnail) GetMetadata() *VideoMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type VideoMetadata struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChannelId            string         `protobuf:"bytes,1,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	PublishedAt          int64          `protobuf:"varint,2,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	Description          string         `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Tags                 []string       `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	CategoryId           string         `protobuf:"bytes,5,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	LiveBroadcastContent string         `protobuf:"bytes,6,opt,name=live_broadcast_content,json=liveBroadcastContent,proto3" json:"live_broadcast_content,omitempty"`
+	DefaultLanguage      string         `protobuf:"bytes,7,opt,name=default_language,json=defaultLanguage,proto3" json:"default_language,omitempty"`
+	Duration             int64          `protobuf:"varint,8,opt,name=duration,proto3" json:"duration,omitempty"`
+	ViewCount            *int64         `protobuf:"varint,9,opt,name=view_count,json=viewCount,proto3,oneof" json:"view_count,omitempty"`
+	LikeCount            *int64         `protobuf:"varint,10,opt,name=like_count,json=likeCount,proto3,oneof" json:"like_count,omitempty"`
+	Parts                []MetadataPart `protobuf:"varint,11,rep,packed,name=parts,proto3,enum=thumbnails.MetadataPart" json:"parts,omitempty"`
+}
+
+func (x *VideoMetadata) Reset() {
+	*x = VideoMetadata{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_thumbnails_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VideoMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VideoMetadata) ProtoMessage() {}
+
+func (x *VideoMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_api_thumbnails_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VideoMetadata.ProtoReflect.Descriptor instead.
+func (*VideoMetadata) Descriptor() ([]byte, []int) {
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *VideoMetadata) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *VideoMetadata) GetPublishedAt() int64 {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return 0
+}
+
+func (x *VideoMetadata) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *VideoMetadata) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *VideoMetadata) GetCategoryId() string {
+	if x != nil {
+		return x.CategoryId
+	}
+	return ""
+}
+
+func (x *VideoMetadata) GetLiveBroadcastContent() string {
+	if x != nil {
+		return x.LiveBroadcastContent
+	}
+	return ""
+}
+
+func (x *VideoMetadata) GetDefaultLanguage() string {
+	if x != nil {
+		return x.DefaultLanguage
+	}
+	return ""
+}
+
+func (x *VideoMetadata) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *VideoMetadata) GetViewCount() int64 {
+	if x != nil && x.ViewCount != nil {
+		return *x.ViewCount
+	}
+	return 0
+}
+
+func (x *VideoMetadata) GetLikeCount() int64 {
+	if x != nil && x.LikeCount != nil {
+		return *x.LikeCount
+	}
+	return 0
+}
+
+func (x *VideoMetadata) GetParts() []MetadataPart {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
 type ErrorResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Url          string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	ErrorMessage string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Url          string    `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	ErrorMessage string    `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Code         ErrorCode `protobuf:"varint,3,opt,name=code,proto3,enum=thumbnails.ErrorCode" json:"code,omitempty"`
 }
 
 func (x *ErrorResponse) Reset() {
 	*x = ErrorResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_thumbnails_proto_msgTypes[3]
+		mi := &file_api_thumbnails_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -234,7 +889,7 @@ func (x *ErrorResponse) String() string {
 func (*ErrorResponse) ProtoMessage() {}
 
 func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_thumbnails_proto_msgTypes[3]
+	mi := &file_api_thumbnails_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -247,7 +902,7 @@ func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ErrorResponse.ProtoReflect.Descriptor instead.
 func (*ErrorResponse) Descriptor() ([]byte, []int) {
-	return file_api_thumbnails_proto_rawDescGZIP(), []int{3}
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ErrorResponse) GetUrl() string {
@@ -264,22 +919,30 @@ func (x *ErrorResponse) GetErrorMessage() string {
 	return ""
 }
 
+func (x *ErrorResponse) GetCode() ErrorCode {
+	if x != nil {
+		return x.Code
+	}
+	return ErrorCode_ERROR_UNSPECIFIED
+}
+
 type ThumbnailResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to Content:
-	//
 	//	*ThumbnailResponse_Thumbnail
 	//	*ThumbnailResponse_Error
-	Content isThumbnailResponse_Content `protobuf_oneof:"content"`
+	Content      isThumbnailResponse_Content `protobuf_oneof:"content"`
+	RequestIndex int32                       `protobuf:"varint,3,opt,name=request_index,json=requestIndex,proto3" json:"request_index,omitempty"`
+	RequestedUrl string                      `protobuf:"bytes,4,opt,name=requested_url,json=requestedUrl,proto3" json:"requested_url,omitempty"`
 }
 
 func (x *ThumbnailResponse) Reset() {
 	*x = ThumbnailResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_thumbnails_proto_msgTypes[4]
+		mi := &file_api_thumbnails_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -292,7 +955,7 @@ func (x *ThumbnailResponse) String() string {
 func (*ThumbnailResponse) ProtoMessage() {}
 
 func (x *ThumbnailResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_thumbnails_proto_msgTypes[4]
+	mi := &file_api_thumbnails_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -305,7 +968,7 @@ func (x *ThumbnailResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ThumbnailResponse.ProtoReflect.Descriptor instead.
 func (*ThumbnailResponse) Descriptor() ([]byte, []int) {
-	return file_api_thumbnails_proto_rawDescGZIP(), []int{4}
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{5}
 }
 
 func (m *ThumbnailResponse) GetContent() isThumbnailResponse_Content {
@@ -329,6 +992,20 @@ func (x *ThumbnailResponse) GetError() *ErrorResponse {
 	return nil
 }
 
+func (x *ThumbnailResponse) GetRequestIndex() int32 {
+	if x != nil {
+		return x.RequestIndex
+	}
+	return 0
+}
+
+func (x *ThumbnailResponse) GetRequestedUrl() string {
+	if x != nil {
+		return x.RequestedUrl
+	}
+	return ""
+}
+
 type isThumbnailResponse_Content interface {
 	isThumbnailResponse_Content()
 }
@@ -356,7 +1033,7 @@ type ListThumbnailResponse struct {
 func (x *ListThumbnailResponse) Reset() {
 	*x = ListThumbnailResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_thumbnails_proto_msgTypes[5]
+		mi := &file_api_thumbnails_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -369,7 +1046,7 @@ func (x *ListThumbnailResponse) String() string {
 func (*ListThumbnailResponse) ProtoMessage() {}
 
 func (x *ListThumbnailResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_thumbnails_proto_msgTypes[5]
+	mi := &file_api_thumbnails_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -382,7 +1059,7 @@ func (x *ListThumbnailResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListThumbnailResponse.ProtoReflect.Descriptor instead.
 func (*ListThumbnailResponse) Descriptor() ([]byte, []int) {
-	return file_api_thumbnails_proto_rawDescGZIP(), []int{5}
+	return file_api_thumbnails_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ListThumbnailResponse) GetThumbnails() []*ThumbnailResponse {
@@ -397,59 +1074,187 @@ var File_api_thumbnails_proto protoreflect.FileDescriptor
 var file_api_thumbnails_proto_rawDesc = []byte{
 	0x0a, 0x14, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73,
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69,
-	0x6c, 0x73, 0x22, 0x27, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61,
-	0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x22, 0x53, 0x0a, 0x14, 0x4c,
-	0x69, 0x73, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x08, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69,
-	0x6c, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73,
-	0x22, 0xa9, 0x01, 0x0a, 0x09, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x12, 0x0e,
-	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10,
-	0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c,
-	0x12, 0x22, 0x0a, 0x0c, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54,
-	0x69, 0x74, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69,
-	0x64, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68,
-	0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65,
-	0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x22, 0x46, 0x0a, 0x0d,
-	0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a,
-	0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12,
-	0x23, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x22, 0x88, 0x01, 0x0a, 0x11, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61,
-	0x69, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x09, 0x74, 0x68,
-	0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
-	0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x54, 0x68, 0x75, 0x6d, 0x62,
-	0x6e, 0x61, 0x69, 0x6c, 0x48, 0x00, 0x52, 0x09, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69,
-	0x6c, 0x12, 0x31, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x19, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x45, 0x72,
-	0x72, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x05, 0x65,
-	0x72, 0x72, 0x6f, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22,
+	0x6c, 0x73, 0x22, 0xdc, 0x03, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e,
+	0x61, 0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x36, 0x0a, 0x0a,
+	0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x16, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x52, 0x65,
+	0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61,
+	0x69, 0x6c, 0x73, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x52, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x14, 0x0a, 0x05,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64,
+	0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x25, 0x0a, 0x03, 0x66, 0x69,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e,
+	0x61, 0x69, 0x6c, 0x73, 0x2e, 0x46, 0x69, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x03, 0x66, 0x69,
+	0x74, 0x12, 0x2f, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x17, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x49,
+	0x6d, 0x61, 0x67, 0x65, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x12, 0x30, 0x0a, 0x08, 0x64, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c,
+	0x73, 0x2e, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x52, 0x08, 0x64, 0x65, 0x6c, 0x69,
+	0x76, 0x65, 0x72, 0x79, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x66, 0x5f, 0x6e, 0x6f, 0x6e, 0x65, 0x5f,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x69, 0x66, 0x4e,
+	0x6f, 0x6e, 0x65, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x26, 0x0a, 0x0c, 0x69, 0x6e, 0x63, 0x6c,
+	0x75, 0x64, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00,
+	0x52, 0x0b, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x2e, 0x0a, 0x05, 0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0e, 0x32,
+	0x18, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x50, 0x61, 0x72, 0x74, 0x52, 0x05, 0x70, 0x61, 0x72, 0x74, 0x73,
+	0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x66, 0x69, 0x6c,
+	0x65, 0x22, 0x53, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61,
+	0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x08, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x74, 0x68,
+	0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x68, 0x75, 0x6d,
+	0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x22, 0xb0, 0x04, 0x0a, 0x09, 0x54, 0x68, 0x75, 0x6d, 0x62,
+	0x6e, 0x61, 0x69, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69,
+	0x74, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x66, 0x69,
+	0x6c, 0x65, 0x12, 0x36, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61,
+	0x69, 0x6c, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a,
+	0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x34, 0x0a, 0x09, 0x61, 0x76,
+	0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x16, 0x2e,
+	0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x6c,
+	0x75, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x6d, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x69, 0x6d, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x66, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x66, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x41, 0x74, 0x12, 0x19, 0x0a, 0x08,
+	0x66, 0x69, 0x6c, 0x65, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x66, 0x69, 0x6c, 0x65, 0x55, 0x72, 0x6c, 0x12, 0x2d, 0x0a, 0x13, 0x66, 0x69, 0x6c, 0x65, 0x5f,
+	0x75, 0x72, 0x6c, 0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x66, 0x69, 0x6c, 0x65, 0x55, 0x72, 0x6c, 0x45, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x65, 0x74, 0x61, 0x67, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x65, 0x74, 0x61, 0x67, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x61,
+	0x73, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x12,
+	0x21, 0x0a, 0x0c, 0x6e, 0x6f, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18,
+	0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6e, 0x6f, 0x74, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69,
+	0x65, 0x64, 0x12, 0x35, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x11,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c,
+	0x73, 0x2e, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x22, 0xbb, 0x03, 0x0a, 0x0d, 0x56, 0x69,
+	0x64, 0x65, 0x6f, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x75,
+	0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0b, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x41, 0x74, 0x12, 0x20, 0x0a,
+	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x61, 0x67, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x5f,
+	0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f,
+	0x72, 0x79, 0x49, 0x64, 0x12, 0x34, 0x0a, 0x16, 0x6c, 0x69, 0x76, 0x65, 0x5f, 0x62, 0x72, 0x6f,
+	0x61, 0x64, 0x63, 0x61, 0x73, 0x74, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x6c, 0x69, 0x76, 0x65, 0x42, 0x72, 0x6f, 0x61, 0x64, 0x63,
+	0x61, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x64, 0x65,
+	0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x4c, 0x61, 0x6e,
+	0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x22, 0x0a, 0x0a, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x09, 0x76, 0x69, 0x65, 0x77, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a, 0x6c, 0x69, 0x6b, 0x65, 0x5f, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x48, 0x01, 0x52, 0x09, 0x6c, 0x69, 0x6b,
+	0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x2e, 0x0a, 0x05, 0x70, 0x61, 0x72,
+	0x74, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62,
+	0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x50, 0x61,
+	0x72, 0x74, 0x52, 0x05, 0x70, 0x61, 0x72, 0x74, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x76, 0x69,
+	0x65, 0x77, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x6c, 0x69, 0x6b,
+	0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x71, 0x0a, 0x0d, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x29, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e,
+	0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x43, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0xd2, 0x01, 0x0a, 0x11, 0x54,
+	0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x35, 0x0a, 0x09, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73,
+	0x2e, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x48, 0x00, 0x52, 0x09, 0x74, 0x68,
+	0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x12, 0x31, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61,
+	0x69, 0x6c, 0x73, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12,
+	0x23, 0x0a, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x75, 0x72, 0x6c,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65,
+	0x64, 0x55, 0x72, 0x6c, 0x42, 0x09, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22,
 	0x56, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c,
 	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x0a, 0x54, 0x68, 0x75, 0x6d,
 	0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x74,
 	0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e,
 	0x61, 0x69, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x0a, 0x54, 0x68, 0x75,
-	0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x32, 0xbc, 0x01, 0x0a, 0x10, 0x54, 0x68, 0x75, 0x6d,
-	0x62, 0x6e, 0x61, 0x69, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x56, 0x0a, 0x0d,
-	0x4c, 0x69, 0x73, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x12, 0x20, 0x2e,
-	0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54,
-	0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x21, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x4c, 0x69, 0x73,
-	0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x12, 0x50, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62,
-	0x6e, 0x61, 0x69, 0x6c, 0x12, 0x1f, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c,
-	0x73, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69,
-	0x6c, 0x73, 0x2e, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x2b, 0x5a, 0x29, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x66, 0x6c, 0x75, 0x78, 0x78, 0x31, 0x6f, 0x6e, 0x2f, 0x67, 0x6f,
-	0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2a, 0x49, 0x0a, 0x0a, 0x52, 0x65, 0x73, 0x6f, 0x6c,
+	0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x41, 0x58, 0x52, 0x45, 0x53, 0x10,
+	0x00, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x54, 0x41, 0x4e, 0x44, 0x41, 0x52, 0x44, 0x10, 0x01, 0x12,
+	0x08, 0x0a, 0x04, 0x48, 0x49, 0x47, 0x48, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x45, 0x44,
+	0x49, 0x55, 0x4d, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54,
+	0x10, 0x04, 0x2a, 0x4c, 0x0a, 0x0e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x41, 0x4c, 0x4c, 0x42, 0x41, 0x43, 0x4b,
+	0x5f, 0x4c, 0x4f, 0x57, 0x45, 0x52, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x46, 0x41, 0x4c, 0x4c,
+	0x42, 0x41, 0x43, 0x4b, 0x5f, 0x48, 0x49, 0x47, 0x48, 0x45, 0x52, 0x10, 0x01, 0x12, 0x11, 0x0a,
+	0x0d, 0x46, 0x41, 0x4c, 0x4c, 0x42, 0x41, 0x43, 0x4b, 0x5f, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x02,
+	0x2a, 0x38, 0x0a, 0x07, 0x46, 0x69, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x46,
+	0x49, 0x54, 0x5f, 0x43, 0x4f, 0x4e, 0x54, 0x41, 0x49, 0x4e, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09,
+	0x46, 0x49, 0x54, 0x5f, 0x43, 0x4f, 0x56, 0x45, 0x52, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x46,
+	0x49, 0x54, 0x5f, 0x45, 0x58, 0x41, 0x43, 0x54, 0x10, 0x02, 0x2a, 0x38, 0x0a, 0x0b, 0x49, 0x6d,
+	0x61, 0x67, 0x65, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x0c, 0x0a, 0x08, 0x4f, 0x52, 0x49,
+	0x47, 0x49, 0x4e, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x4a, 0x50, 0x45, 0x47, 0x10,
+	0x01, 0x12, 0x07, 0x0a, 0x03, 0x50, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x57, 0x45,
+	0x42, 0x50, 0x10, 0x03, 0x2a, 0x26, 0x0a, 0x08, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79,
+	0x12, 0x0a, 0x0a, 0x06, 0x49, 0x4e, 0x4c, 0x49, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a,
+	0x53, 0x49, 0x47, 0x4e, 0x45, 0x44, 0x5f, 0x55, 0x52, 0x4c, 0x10, 0x01, 0x2a, 0x40, 0x0a, 0x0c,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x50, 0x61, 0x72, 0x74, 0x12, 0x0b, 0x0a, 0x07,
+	0x53, 0x4e, 0x49, 0x50, 0x50, 0x45, 0x54, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x4f, 0x4e,
+	0x54, 0x45, 0x4e, 0x54, 0x5f, 0x44, 0x45, 0x54, 0x41, 0x49, 0x4c, 0x53, 0x10, 0x01, 0x12, 0x0e,
+	0x0a, 0x0a, 0x53, 0x54, 0x41, 0x54, 0x49, 0x53, 0x54, 0x49, 0x43, 0x53, 0x10, 0x02, 0x2a, 0xb3,
+	0x01, 0x0a, 0x09, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x15, 0x0a, 0x11,
+	0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x55,
+	0x52, 0x4c, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e,
+	0x44, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x52, 0x49, 0x56, 0x41, 0x54, 0x45, 0x10, 0x03,
+	0x12, 0x12, 0x0a, 0x0e, 0x51, 0x55, 0x4f, 0x54, 0x41, 0x5f, 0x45, 0x58, 0x43, 0x45, 0x45, 0x44,
+	0x45, 0x44, 0x10, 0x04, 0x12, 0x14, 0x0a, 0x10, 0x55, 0x50, 0x53, 0x54, 0x52, 0x45, 0x41, 0x4d,
+	0x5f, 0x54, 0x49, 0x4d, 0x45, 0x4f, 0x55, 0x54, 0x10, 0x05, 0x12, 0x12, 0x0a, 0x0e, 0x55, 0x50,
+	0x53, 0x54, 0x52, 0x45, 0x41, 0x4d, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x06, 0x12, 0x0f,
+	0x0a, 0x0b, 0x43, 0x41, 0x43, 0x48, 0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x07, 0x12,
+	0x13, 0x0a, 0x0f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x45,
+	0x53, 0x54, 0x10, 0x08, 0x32, 0x95, 0x02, 0x0a, 0x10, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61,
+	0x69, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x56, 0x0a, 0x0d, 0x4c, 0x69, 0x73,
+	0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x12, 0x20, 0x2e, 0x74, 0x68, 0x75,
+	0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x68, 0x75, 0x6d,
+	0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x74,
+	0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x68,
+	0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x50, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69,
+	0x6c, 0x12, 0x1f, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x47,
+	0x65, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e,
+	0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x12, 0x57, 0x0a, 0x10, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x54, 0x68, 0x75,
+	0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x12, 0x20, 0x2e, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e,
+	0x61, 0x69, 0x6c, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61,
+	0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x74, 0x68, 0x75, 0x6d,
+	0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x54, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0x38, 0x5a, 0x36,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x66, 0x6c, 0x75, 0x78, 0x78,
+	0x31, 0x6f, 0x6e, 0x2f, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x6e, 0x61, 0x69, 0x6c, 0x73, 0x5f, 0x6d,
+	0x69, 0x63, 0x72, 0x6f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x67, 0x72, 0x70, 0x63,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -464,29 +1269,51 @@ func file_api_thumbnails_proto_rawDescGZIP() []byte {
 	return file_api_thumbnails_proto_rawDescData
 }
 
-var file_api_thumbnails_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_api_thumbnails_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_api_thumbnails_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_api_thumbnails_proto_goTypes = []interface{}{
-	(*GetThumbnailRequest)(nil),   // 0: thumbnails.GetThumbnailRequest
-	(*ListThumbnailRequest)(nil),  // 1: thumbnails.ListThumbnailRequest
-	(*Thumbnail)(nil),             // 2: thumbnails.Thumbnail
-	(*ErrorResponse)(nil),         // 3: thumbnails.ErrorResponse
-	(*ThumbnailResponse)(nil),     // 4: thumbnails.ThumbnailResponse
-	(*ListThumbnailResponse)(nil), // 5: thumbnails.ListThumbnailResponse
+	(Resolution)(0),               // 0: thumbnails.Resolution
+	(FallbackPolicy)(0),           // 1: thumbnails.FallbackPolicy
+	(FitMode)(0),                  // 2: thumbnails.FitMode
+	(ImageFormat)(0),              // 3: thumbnails.ImageFormat
+	(Delivery)(0),                 // 4: thumbnails.Delivery
+	(MetadataPart)(0),             // 5: thumbnails.MetadataPart
+	(ErrorCode)(0),                // 6: thumbnails.ErrorCode
+	(*GetThumbnailRequest)(nil),   // 7: thumbnails.GetThumbnailRequest
+	(*ListThumbnailRequest)(nil),  // 8: thumbnails.ListThumbnailRequest
+	(*Thumbnail)(nil),             // 9: thumbnails.Thumbnail
+	(*VideoMetadata)(nil),         // 10: thumbnails.VideoMetadata
+	(*ErrorResponse)(nil),         // 11: thumbnails.ErrorResponse
+	(*ThumbnailResponse)(nil),     // 12: thumbnails.ThumbnailResponse
+	(*ListThumbnailResponse)(nil), // 13: thumbnails.ListThumbnailResponse
 }
 var file_api_thumbnails_proto_depIdxs = []int32{
-	0, // 0: thumbnails.ListThumbnailRequest.Requests:type_name -> thumbnails.GetThumbnailRequest
-	2, // 1: thumbnails.ThumbnailResponse.thumbnail:type_name -> thumbnails.Thumbnail
-	3, // 2: thumbnails.ThumbnailResponse.error:type_name -> thumbnails.ErrorResponse
-	4, // 3: thumbnails.ListThumbnailResponse.Thumbnails:type_name -> thumbnails.ThumbnailResponse
-	1, // 4: thumbnails.ThumbnailService.ListThumbnail:input_type -> thumbnails.ListThumbnailRequest
-	0, // 5: thumbnails.ThumbnailService.GetThumbnail:input_type -> thumbnails.GetThumbnailRequest
-	5, // 6: thumbnails.ThumbnailService.ListThumbnail:output_type -> thumbnails.ListThumbnailResponse
-	4, // 7: thumbnails.ThumbnailService.GetThumbnail:output_type -> thumbnails.ThumbnailResponse
-	6, // [6:8] is the sub-list for method output_type
-	4, // [4:6] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	0,  // 0: thumbnails.GetThumbnailRequest.resolution:type_name -> thumbnails.Resolution
+	1,  // 1: thumbnails.GetThumbnailRequest.fallback:type_name -> thumbnails.FallbackPolicy
+	2,  // 2: thumbnails.GetThumbnailRequest.fit:type_name -> thumbnails.FitMode
+	3,  // 3: thumbnails.GetThumbnailRequest.format:type_name -> thumbnails.ImageFormat
+	4,  // 4: thumbnails.GetThumbnailRequest.delivery:type_name -> thumbnails.Delivery
+	5,  // 5: thumbnails.GetThumbnailRequest.parts:type_name -> thumbnails.MetadataPart
+	7,  // 6: thumbnails.ListThumbnailRequest.Requests:type_name -> thumbnails.GetThumbnailRequest
+	0,  // 7: thumbnails.Thumbnail.resolution:type_name -> thumbnails.Resolution
+	0,  // 8: thumbnails.Thumbnail.available:type_name -> thumbnails.Resolution
+	10, // 9: thumbnails.Thumbnail.metadata:type_name -> thumbnails.VideoMetadata
+	5,  // 10: thumbnails.VideoMetadata.parts:type_name -> thumbnails.MetadataPart
+	6,  // 11: thumbnails.ErrorResponse.code:type_name -> thumbnails.ErrorCode
+	9,  // 12: thumbnails.ThumbnailResponse.thumbnail:type_name -> thumbnails.Thumbnail
+	11, // 13: thumbnails.ThumbnailResponse.error:type_name -> thumbnails.ErrorResponse
+	12, // 14: thumbnails.ListThumbnailResponse.Thumbnails:type_name -> thumbnails.ThumbnailResponse
+	8,  // 15: thumbnails.ThumbnailService.ListThumbnail:input_type -> thumbnails.ListThumbnailRequest
+	7,  // 16: thumbnails.ThumbnailService.GetThumbnail:input_type -> thumbnails.GetThumbnailRequest
+	8,  // 17: thumbnails.ThumbnailService.StreamThumbnails:input_type -> thumbnails.ListThumbnailRequest
+	13, // 18: thumbnails.ThumbnailService.ListThumbnail:output_type -> thumbnails.ListThumbnailResponse
+	12, // 19: thumbnails.ThumbnailService.GetThumbnail:output_type -> thumbnails.ThumbnailResponse
+	12, // 20: thumbnails.ThumbnailService.StreamThumbnails:output_type -> thumbnails.ThumbnailResponse
+	18, // [18:21] is the sub-list for method output_type
+	15, // [15:18] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_api_thumbnails_proto_init() }
@@ -532,7 +1359,7 @@ func file_api_thumbnails_proto_init() {
 			}
 		}
 		file_api_thumbnails_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ErrorResponse); i {
+			switch v := v.(*VideoMetadata); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -544,7 +1371,7 @@ func file_api_thumbnails_proto_init() {
 			}
 		}
 		file_api_thumbnails_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ThumbnailResponse); i {
+			switch v := v.(*ErrorResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -556,6 +1383,18 @@ func file_api_thumbnails_proto_init() {
 			}
 		}
 		file_api_thumbnails_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThumbnailResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_thumbnails_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListThumbnailResponse); i {
 			case 0:
 				return &v.state
@@ -568,7 +1407,9 @@ func file_api_thumbnails_proto_init() {
 			}
 		}
 	}
-	file_api_thumbnails_proto_msgTypes[4].OneofWrappers = []interface{}{
+	file_api_thumbnails_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_api_thumbnails_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_api_thumbnails_proto_msgTypes[5].OneofWrappers = []interface{}{
 		(*ThumbnailResponse_Thumbnail)(nil),
 		(*ThumbnailResponse_Error)(nil),
 	}
@@ -577,13 +1418,14 @@ func file_api_thumbnails_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_thumbnails_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      7,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_thumbnails_proto_goTypes,
 		DependencyIndexes: file_api_thumbnails_proto_depIdxs,
+		EnumInfos:         file_api_thumbnails_proto_enumTypes,
 		MessageInfos:      file_api_thumbnails_proto_msgTypes,
 	}.Build()
 	File_api_thumbnails_proto = out.File
@@ -19,8 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ThumbnailService_ListThumbnail_FullMethodName = "/thumbnails.ThumbnailService/ListThumbnail"
-	ThumbnailService_GetThumbnail_FullMethodName  = "/thumbnails.ThumbnailService/GetThumbnail"
+	ThumbnailService_ListThumbnail_FullMethodName    = "/thumbnails.ThumbnailService/ListThumbnail"
+	ThumbnailService_GetThumbnail_FullMethodName     = "/thumbnails.ThumbnailService/GetThumbnail"
+	ThumbnailService_StreamThumbnails_FullMethodName = "/thumbnails.ThumbnailService/StreamThumbnails"
 )
 
 // ThumbnailServiceClient is the client API for ThumbnailService service.
@@ -29,6 +30,7 @@ const (
 type ThumbnailServiceClient interface {
 	ListThumbnail(ctx context.Context, in *ListThumbnailRequest, opts ...grpc.CallOption) (*ListThumbnailResponse, error)
 	GetThumbnail(ctx context.Context, in *GetThumbnailRequest, opts ...grpc.CallOption) (*ThumbnailResponse, error)
+	StreamThumbnails(ctx context.Context, in *ListThumbnailRequest, opts ...grpc.CallOption) (ThumbnailService_StreamThumbnailsClient, error)
 }
 
 type thumbnailServiceClient struct {
@@ -57,12 +59,45 @@ func (c *thumbnailServiceClient) GetThumbnail(ctx context.Context, in *GetThumbn
 	return out, nil
 }
 
+func (c *thumbnailServiceClient) StreamThumbnails(ctx context.Context, in *ListThumbnailRequest, opts ...grpc.CallOption) (ThumbnailService_StreamThumbnailsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ThumbnailService_ServiceDesc.Streams[0], ThumbnailService_StreamThumbnails_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &thumbnailServiceStreamThumbnailsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ThumbnailService_StreamThumbnailsClient interface {
+	Recv() (*ThumbnailResponse, error)
+	grpc.ClientStream
+}
+
+type thumbnailServiceStreamThumbnailsClient struct {
+	grpc.ClientStream
+}
+
+func (x *thumbnailServiceStreamThumbnailsClient) Recv() (*ThumbnailResponse, error) {
+	m := new(ThumbnailResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ThumbnailServiceServer is the server API for ThumbnailService service.
 // All implementations must embed UnimplementedThumbnailServiceServer
 // for forward compatibility
 type ThumbnailServiceServer interface {
 	ListThumbnail(context.Context, *ListThumbnailRequest) (*ListThumbnailResponse, error)
 	GetThumbnail(context.Context, *GetThumbnailRequest) (*ThumbnailResponse, error)
+	StreamThumbnails(*ListThumbnailRequest, ThumbnailService_StreamThumbnailsServer) error
 	mustEmbedUnimplementedThumbnailServiceServer()
 }
 
@@ -76,6 +111,9 @@ func (UnimplementedThumbnailServiceServer) ListThumbnail(context.Context, *ListT
 func (UnimplementedThumbnailServiceServer) GetThumbnail(context.Context, *GetThumbnailRequest) (*ThumbnailResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetThumbnail not implemented")
 }
+func (UnimplementedThumbnailServiceServer) StreamThumbnails(*ListThumbnailRequest, ThumbnailService_StreamThumbnailsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamThumbnails not implemented")
+}
 func (UnimplementedThumbnailServiceServer) mustEmbedUnimplementedThumbnailServiceServer() {}
 
 // UnsafeThumbnailServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -125,6 +163,27 @@ func _ThumbnailService_GetThumbnail_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ThumbnailService_StreamThumbnails_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListThumbnailRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ThumbnailServiceServer).StreamThumbnails(m, &thumbnailServiceStreamThumbnailsServer{stream})
+}
+
+type ThumbnailService_StreamThumbnailsServer interface {
+	Send(*ThumbnailResponse) error
+	grpc.ServerStream
+}
+
+type thumbnailServiceStreamThumbnailsServer struct {
+	grpc.ServerStream
+}
+
+func (x *thumbnailServiceStreamThumbnailsServer) Send(m *ThumbnailResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // ThumbnailService_ServiceDesc is the grpc.ServiceDesc for ThumbnailService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -141,6 +200,12 @@ var ThumbnailService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _ThumbnailService_GetThumbnail_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamThumbnails",
+			Handler:       _ThumbnailService_StreamThumbnails_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "api/thumbnails.proto",
 }
@@ -51,3 +51,26 @@ func (s *ThumbnailService) GetThumbnail(ctx context.Context, req *proto.GetThumb
 	slog.Info("Response sent succesfully", GetResponseStat(resp))
 	return resp, err
 }
+
+func (s *ThumbnailService) StreamThumbnails(req *proto.ListThumbnailRequest,
+	stream proto.ThumbnailService_StreamThumbnailsServer) error {
+	// Responses are counted as they are sent, not kept till the end of stream
+	var stat ResponseStat
+
+	err := s.f.StreamThumbnailList(stream.Context(), req, func(resp *proto.ThumbnailResponse) error {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		stat.Add(resp)
+		return nil
+	})
+
+	if err != nil {
+		slog.Info("Requested:", req.String())
+		slog.Error("Stream was interrupted", err)
+		return err
+	}
+
+	slog.Info("Stream succesfully sent", stat.String())
+	return nil
+}
@@ -6,20 +6,30 @@ import (
 	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
 )
 
-// GetResponseStat count sum of errors and correct Thumbnails.
-// Used by gRPC service to check and log the traffic.
-func GetResponseStat(srcList ...*proto.ThumbnailResponse) string {
-	var (
-		thumbCounter, errorCounter int = 0, 0
-	)
+// ResponseStat counts errors and correct Thumbnails of sent responses
+type ResponseStat struct {
+	thumbCounter, errorCounter int
+}
 
+// Add counts responses
+func (r *ResponseStat) Add(srcList ...*proto.ThumbnailResponse) {
 	for _, resp := range srcList {
 		if thumb := resp.GetThumbnail(); thumb != nil {
-			thumbCounter++
+			r.thumbCounter++
 		} else if err := resp.GetError(); err != nil {
-			errorCounter++
+			r.errorCounter++
 		}
 	}
+}
 
-	return fmt.Sprintf("successes: %d; errors: %d.", thumbCounter, errorCounter)
+func (r ResponseStat) String() string {
+	return fmt.Sprintf("successes: %d; errors: %d.", r.thumbCounter, r.errorCounter)
+}
+
+// GetResponseStat count sum of errors and correct Thumbnails.
+// Used by gRPC service to check and log the traffic.
+func GetResponseStat(srcList ...*proto.ThumbnailResponse) string {
+	var stat ResponseStat
+	stat.Add(srcList...)
+	return stat.String()
 }
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	// Current module
+	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
+	"github.com/fluxx1on/thumbnails_microservice/libs/upstream"
+)
+
+// HealthPath is a path of HTTP health check
+const HealthPath = "/healthz"
+
+func servingStatus(state upstream.State) healthpb.HealthCheckResponse_ServingStatus {
+	if state == upstream.StateOpen {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+// registerHealth adds gRPC health service. Server itself is the empty service name,
+// every YouTube host is a service which isn't serving while its circuit breaker is open.
+func (g *GRPC) registerHealth() {
+	g.health = health.NewServer()
+	healthpb.RegisterHealthServer(g.server, g.health)
+
+	for _, host := range youtube.Breakers.States() {
+		g.health.SetServingStatus(host.Host, servingStatus(host.State))
+	}
+	youtube.Breakers.OnChange(func(host string, state upstream.State) {
+		g.health.SetServingStatus(host, servingStatus(state))
+	})
+}
+
+type healthReport struct {
+	// Status is degraded if any upstream is down; cached thumbnails are still served
+	Status    string            `json:"status"`
+	Upstreams map[string]string `json:"upstreams"`
+}
+
+// healthHandler reports states of upstream circuit breakers
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	report := healthReport{
+		Status:    "ok",
+		Upstreams: make(map[string]string),
+	}
+	for _, host := range youtube.Breakers.States() {
+		report.Upstreams[host.Host] = host.State.String()
+		if host.State == upstream.StateOpen {
+			report.Status = "degraded"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	// Current module
+	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
+	"github.com/fluxx1on/thumbnails_microservice/internal/gateway"
+	"github.com/fluxx1on/thumbnails_microservice/internal/media"
+	"github.com/fluxx1on/thumbnails_microservice/libs/signing"
+)
+
+// startHTTP runs HTTP/JSON gateway, health check, runtime statistic and server of signed media links if address is configured
+func (g *GRPC) startHTTP(cfg *config.HTTPServer, gw *gateway.Gateway, urlSigner *signing.URLSigner) {
+	if cfg.Address == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, healthHandler)
+	mux.HandleFunc(StatsPath, g.statsHandler)
+	gw.Register(mux)
+	if urlSigner != nil {
+		mux.Handle(signing.MediaPath, media.NewHandler(urlSigner))
+	}
+
+	g.httpServer = &http.Server{
+		Addr:              cfg.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := g.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Failed to serve HTTP", err)
+		}
+	}()
+
+	slog.Info("HTTP server started on address:", cfg.Address)
+}
+
+func (g *GRPC) stopHTTP() {
+	if g.httpServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := g.httpServer.Shutdown(ctx); err != nil {
+		slog.Warn("HTTP server shutdown", err)
+	}
+}
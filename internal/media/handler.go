@@ -0,0 +1,59 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
+	"github.com/fluxx1on/thumbnails_microservice/libs/signing"
+)
+
+// Handler serves files of media store by signed links
+type Handler struct {
+	signer *signing.URLSigner
+}
+
+func NewHandler(signer *signing.URLSigner) *Handler {
+	return &Handler{signer: signer}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, signing.MediaPath)
+	digest, suffix, ok := blobstore.ParseName(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now()
+	expires, err := h.signer.Verify(name, r.URL.Query(), now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	var data []byte
+	if suffix == "" {
+		data = blobstore.Media.Get(digest)
+	} else {
+		data = blobstore.Media.GetDerived(digest, suffix)
+	}
+	if data == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(data))
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(expires.Sub(now).Seconds())))
+	w.Header().Set("ETag", `"`+name+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
@@ -0,0 +1,300 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+// CoalesceStats is a statistic of upstream fetches deduplication
+type CoalesceStats struct {
+	// Fetched is a number of variants fetched from YouTube
+	Fetched uint64
+	// Coalesced is a number of variants answered by in-flight fetch of another request
+	Coalesced uint64
+}
+
+// Ratio is a share of variants answered without own fetch
+func (s CoalesceStats) Ratio() float64 {
+	if s.Fetched+s.Coalesced == 0 {
+		return 0
+	}
+	return float64(s.Coalesced) / float64(s.Fetched+s.Coalesced)
+}
+
+func (s CoalesceStats) String() string {
+	return fmt.Sprintf("fetched %d; coalesced %d; ratio %.2f;", s.Fetched, s.Coalesced, s.Ratio())
+}
+
+// flight is an in-flight upstream fetch of variants
+type flight struct {
+	variants []utils.Variant
+	// waiters is a number of calls waiting for flight, guarded by Coalescer.mu.
+	// Flight is canceled when the last of them stops waiting.
+	waiters int
+	cancel  context.CancelFunc
+
+	// Result of variants[i] is kept at index i, resolved[i] is closed once it is set
+	resolved  []chan struct{}
+	responses []*proto.ThumbnailResponse
+	errs      []error
+}
+
+func newFlight(variants []utils.Variant) *flight {
+	f := &flight{
+		variants:  variants,
+		resolved:  make([]chan struct{}, len(variants)),
+		responses: make([]*proto.ThumbnailResponse, len(variants)),
+		errs:      make([]error, len(variants)),
+	}
+	for i := range f.resolved {
+		f.resolved[i] = make(chan struct{})
+	}
+	return f
+}
+
+// serves returns index of flight variant whose result answers variant
+func (f *flight) serves(variant utils.Variant) (int, bool) {
+	for i, fetched := range f.variants {
+		if fetched.ID == variant.ID &&
+			fetched.Resolution == variant.Resolution &&
+			fetched.Fallback == variant.Fallback &&
+			fetched.Parts.Contains(variant.Parts) &&
+			(!fetched.MetadataOnly || variant.MetadataOnly) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// isResolved reports that result of variants[i] is set
+func (f *flight) isResolved(i int) bool {
+	select {
+	case <-f.resolved[i]:
+		return true
+	default:
+		return false
+	}
+}
+
+// resolve keeps result of fetched variant. It is a youtube.ResolveFunc of fetch.
+// Equal variants get results in order of resolve.
+func (f *flight) resolve(variant utils.Variant, resp *proto.ThumbnailResponse, err error) {
+	for i, fetched := range f.variants {
+		if fetched == variant && !f.isResolved(i) {
+			f.responses[i], f.errs[i] = resp, err
+			close(f.resolved[i])
+			return
+		}
+	}
+}
+
+// answer picks result of variants[i] for waiting variant.
+// Responses of joined variants are wrapped anew, since callers set request fields of them.
+func (f *flight) answer(wait waiter) (*proto.ThumbnailResponse, error) {
+	resp, err := f.responses[wait.index], f.errs[wait.index]
+	if wait.own && resp != nil {
+		return resp, nil
+	}
+	if thumb := resp.GetThumbnail(); thumb != nil && wait.variant.Answers(thumb) {
+		return &proto.ThumbnailResponse{Content: resp.Content}, nil
+	}
+	if err == nil {
+		err = youtube.ErrNotFound
+	}
+	return nil, err
+}
+
+// FetchFunc downloads variants from upstream and passes every one of them to resolved.
+// Calls of resolved are sequential.
+type FetchFunc func(context.Context, youtube.ResolveFunc, ...utils.Variant)
+
+// ResultFunc receives response or error of variant. Its error stops Coalescer.Do.
+type ResultFunc func(variant utils.Variant, resp *proto.ThumbnailResponse, err error) error
+
+// Coalescer deduplicates concurrent upstream fetches keyed by video ID
+type Coalescer struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+
+	fetched, coalesced atomic.Uint64
+}
+
+func NewCoalescer() *Coalescer {
+	return &Coalescer{flights: make(map[string]*flight)}
+}
+
+func (c *Coalescer) Stats() CoalesceStats {
+	return CoalesceStats{Fetched: c.fetched.Load(), Coalesced: c.coalesced.Load()}
+}
+
+// waiter is a variant waiting for result of flight variant at index
+type waiter struct {
+	flight  *flight
+	index   int
+	variant utils.Variant
+	// own reports that variant is fetched by the waiting call
+	own bool
+}
+
+// Do fetches variants by fetch and passes every one of them to result as soon as it is resolved.
+// Variants which are fetched by in-flight call of another request wait for its result instead of own fetch.
+// Only the fetching call gets results of fetch, so it is called once per flight.
+// Flight isn't canceled with ctx of the fetching call, since it serves other requests too.
+// Every call waits for results while its ctx isn't done, and flight is canceled
+// once no call waits for it. Deadlines of fetch phases are applied by fetch.
+// Calls of result are sequential and are made by the calling goroutine.
+func (c *Coalescer) Do(ctx context.Context, fetch FetchFunc, result ResultFunc, variants ...utils.Variant) error {
+	var (
+		own     = make([]utils.Variant, 0, len(variants))
+		waiting = make([]waiter, 0, len(variants))
+		joined  = make(map[*flight]bool)
+	)
+
+	c.mu.Lock()
+	for _, variant := range variants {
+		if f, ok := c.flights[variant.ID]; ok {
+			if index, ok := f.serves(variant); ok {
+				waiting = append(waiting, waiter{flight: f, index: index, variant: variant})
+				if !joined[f] {
+					joined[f] = true
+					f.waiters++
+				}
+				continue
+			}
+		}
+		own = append(own, variant)
+	}
+	coalesced := len(waiting)
+
+	var (
+		f        *flight
+		fetchCtx context.Context
+	)
+	if len(own) != 0 {
+		f = newFlight(own)
+		f.waiters = 1
+		fetchCtx, f.cancel = context.WithCancel(detached{parent: ctx})
+		for index, variant := range own {
+			c.flights[variant.ID] = f
+			waiting = append(waiting, waiter{flight: f, index: index, variant: variant, own: true})
+		}
+		joined[f] = true
+	}
+	c.mu.Unlock()
+
+	defer func() {
+		for joinedFlight := range joined {
+			c.leave(joinedFlight)
+		}
+	}()
+
+	c.fetched.Add(uint64(len(own)))
+	c.coalesced.Add(uint64(coalesced))
+
+	if f != nil {
+		go c.run(fetchCtx, f, fetch)
+	}
+
+	// Waiters are passed to result in order of resolve
+	var (
+		ready  = make(chan int, len(waiting))
+		stop   = make(chan struct{})
+		passed = make([]bool, len(waiting))
+	)
+	defer close(stop)
+	for i, wait := range waiting {
+		go func(i int, resolved <-chan struct{}) {
+			select {
+			case <-resolved:
+				ready <- i
+			case <-stop:
+			}
+		}(i, wait.flight.resolved[wait.index])
+	}
+
+	for left := len(waiting); left > 0; left-- {
+		select {
+		case i := <-ready:
+			passed[i] = true
+			resp, err := waiting[i].flight.answer(waiting[i])
+			if err := result(waiting[i].variant, resp, err); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			for i, wait := range waiting {
+				if passed[i] {
+					continue
+				}
+				if err := result(wait.variant, nil, abandoned(ctx)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// run fetches variants of flight and lets waiting calls read results.
+// Variants missing in results of fetch aren't found.
+func (c *Coalescer) run(ctx context.Context, f *flight, fetch FetchFunc) {
+	fetch(ctx, f.resolve, f.variants...)
+	for i, variant := range f.variants {
+		if !f.isResolved(i) {
+			f.resolve(variant, nil, youtube.ErrNotFound)
+		}
+	}
+
+	c.mu.Lock()
+	for _, variant := range f.variants {
+		if c.flights[variant.ID] == f {
+			delete(c.flights, variant.ID)
+		}
+	}
+	c.mu.Unlock()
+	f.cancel()
+}
+
+// leave stops waiting for flight. The last call cancels unfinished flight,
+// which can't be joined anymore.
+func (c *Coalescer) leave(f *flight) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f.waiters--
+	if f.waiters != 0 {
+		return
+	}
+	for _, variant := range f.variants {
+		if c.flights[variant.ID] == f {
+			delete(c.flights, variant.ID)
+		}
+	}
+	f.cancel()
+}
+
+// detached is a context with values of parent, but without its deadline and cancel.
+// Go 1.20 has no context.WithoutCancel.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+// abandoned is an error of variant whose call stopped waiting for flight
+func abandoned(ctx context.Context) error {
+	return &youtube.Error{Code: proto.ErrorCode_UPSTREAM_TIMEOUT, Err: ctx.Err()}
+}
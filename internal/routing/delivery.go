@@ -0,0 +1,118 @@
+package routing
+
+import (
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+	"golang.org/x/exp/slog"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+const (
+	ErrSignedURLDisabled = "Signed URL delivery is disabled"
+)
+
+// newVariant builds variant of valid request.
+// ErrorResponse is returned if derivative or delivery can't be provided.
+func (t *ThumbnailFetchService) newVariant(videoID string, req *proto.GetThumbnailRequest) (
+	utils.Variant, *proto.ThumbnailResponse) {
+	variant := utils.NewVariant(videoID, req)
+
+	if err := variant.Spec.Validate(); err != nil {
+		return variant, transformErrorResponse(req.GetUrl(), err)
+	}
+	if variant.Delivery == proto.Delivery_SIGNED_URL && t.urlSigner == nil {
+		return variant, utils.NewErrorThumbnailResponse(req.GetUrl(),
+			proto.ErrorCode_INVALID_REQUEST, ErrSignedURLDisabled)
+	}
+	return variant, nil
+}
+
+// respond makes response answering variant from original thumbnail
+func (t *ThumbnailFetchService) respond(resp *proto.ThumbnailResponse, variant utils.Variant) *proto.ThumbnailResponse {
+	if variant.MetadataOnly {
+		return metadataResponse(resp)
+	}
+
+	// Etag of derivative is known from original digest, so image isn't derived for client having it
+	if thumb := resp.GetThumbnail(); thumb != nil && variant.Matches(variant.Etag(thumb.GetEtag())) {
+		return notModifiedResponse(thumb, variant)
+	}
+
+	derived := deriveResponse(resp, variant)
+	return t.deliverResponse(derived, resp.GetThumbnail(), variant)
+}
+
+// metadataResponse keeps meta data of thumbnail without image
+func metadataResponse(resp *proto.ThumbnailResponse) *proto.ThumbnailResponse {
+	thumb := resp.GetThumbnail()
+	if thumb == nil || len(thumb.GetFile()) == 0 {
+		return resp
+	}
+
+	kept := protobuf.Clone(thumb).(*proto.Thumbnail)
+	kept.File = nil
+
+	return &proto.ThumbnailResponse{
+		Content: &proto.ThumbnailResponse_Thumbnail{
+			Thumbnail: kept,
+		},
+	}
+}
+
+// notModifiedResponse keeps meta data of thumbnail without image.
+// Size and type of derivative aren't known without reading it, so they are left empty.
+func notModifiedResponse(thumb *proto.Thumbnail, variant utils.Variant) *proto.ThumbnailResponse {
+	kept := protobuf.Clone(thumb).(*proto.Thumbnail)
+	kept.File = nil
+	kept.NotModified = true
+	kept.Etag = variant.Etag(thumb.GetEtag())
+	if !variant.Spec.IsOriginal() {
+		kept.Width, kept.Height, kept.MimeType = 0, 0, ""
+	}
+
+	return &proto.ThumbnailResponse{
+		Content: &proto.ThumbnailResponse_Thumbnail{
+			Thumbnail: kept,
+		},
+	}
+}
+
+// deliverResponse replaces image of response by signed link if variant requests it.
+// Image is kept inline if it can't be stored to media store.
+func (t *ThumbnailFetchService) deliverResponse(resp *proto.ThumbnailResponse, original *proto.Thumbnail,
+	variant utils.Variant) *proto.ThumbnailResponse {
+	thumb := resp.GetThumbnail()
+	if thumb == nil || variant.Delivery != proto.Delivery_SIGNED_URL || t.urlSigner == nil {
+		return resp
+	}
+
+	// Derivative is already stored by deriveResponse
+	var (
+		digest = originalDigest(original)
+		suffix string
+	)
+	if variant.Spec.IsOriginal() {
+		if _, err := blobstore.Media.Put(original.GetFile()); err != nil {
+			slog.Warn("Linked image storing failed", err, curDir)
+			return resp
+		}
+	} else {
+		suffix = variant.Spec.Suffix()
+	}
+
+	link, expires := t.urlSigner.Sign(digest+suffix, time.Now())
+
+	linked := protobuf.Clone(thumb).(*proto.Thumbnail)
+	linked.File = nil
+	linked.FileUrl = link
+	linked.FileUrlExpiresAt = expires.Unix()
+
+	return &proto.ThumbnailResponse{
+		Content: &proto.ThumbnailResponse_Thumbnail{
+			Thumbnail: linked,
+		},
+	}
+}
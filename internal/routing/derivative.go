@@ -0,0 +1,100 @@
+package routing
+
+import (
+	"errors"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
+	"github.com/fluxx1on/thumbnails_microservice/libs/imaging"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+	"golang.org/x/exp/slog"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+const (
+	ErrTransformImage = "Image transformation failed"
+)
+
+// transformErrorResponse is an ErrorResponse of failed transformation
+func transformErrorResponse(url string, err error) *proto.ThumbnailResponse {
+	code := proto.ErrorCode_UPSTREAM_ERROR
+	if errors.Is(err, imaging.ErrInvalidSize) || errors.Is(err, imaging.ErrUnsupportedFormat) {
+		code = proto.ErrorCode_INVALID_REQUEST
+	}
+	return utils.NewErrorThumbnailResponse(url, code, ErrTransformImage+": "+err.Error())
+}
+
+// originalDigest returns digest of original image. Etag is a digest if it's known.
+func originalDigest(thumb *proto.Thumbnail) string {
+	if thumb.GetEtag() != "" {
+		return thumb.GetEtag()
+	}
+	return blobstore.Digest(thumb.GetFile())
+}
+
+// deriveResponse replaces original image of response by derivative described in variant.
+// Derivative is cached by digest of original image.
+func deriveResponse(resp *proto.ThumbnailResponse, variant utils.Variant) *proto.ThumbnailResponse {
+	thumb := resp.GetThumbnail()
+	if thumb == nil || variant.Spec.IsOriginal() {
+		return resp
+	}
+
+	var (
+		key    = utils.ThumbnailKey(thumb)
+		digest = originalDigest(thumb)
+		suffix = variant.Spec.Suffix()
+		result imaging.Result
+		err    error
+	)
+
+	if data := blobstore.Media.GetDerived(digest, suffix); data != nil {
+		result, err = imaging.Inspect(data)
+	}
+	if result.Data == nil || err != nil {
+		result, err = imaging.Transform(thumb.GetFile(), variant.Spec)
+		if err != nil {
+			slog.Debug("Transformation failed", err, key)
+			return transformErrorResponse(variant.ID, err)
+		}
+
+		if err := blobstore.Media.PutDerived(result.Data, digest, suffix); err != nil {
+			slog.Warn("Writing derivative file denied", err, curDir)
+		}
+	}
+
+	derived := protobuf.Clone(thumb).(*proto.Thumbnail)
+	derived.File = result.Data
+	derived.Width = int32(result.Width)
+	derived.Height = int32(result.Height)
+	derived.MimeType = result.MimeType
+	derived.Etag = digest + suffix
+
+	return &proto.ThumbnailResponse{
+		Content: &proto.ThumbnailResponse_Thumbnail{
+			Thumbnail: derived,
+		},
+	}
+}
+
+// matchVariants pairs every response with requested variant it answers.
+// Result keeps index of variant for every response, -1 if nothing matched.
+func matchVariants(variants []utils.Variant, responses []*proto.ThumbnailResponse) []int {
+	var (
+		used    = make([]bool, len(variants))
+		matched = make([]int, len(responses))
+	)
+
+	for r, resp := range responses {
+		matched[r] = -1
+		for i, variant := range variants {
+			if !used[i] && variant.Answers(resp.GetThumbnail()) {
+				used[i] = true
+				matched[r] = i
+				break
+			}
+		}
+	}
+
+	return matched
+}
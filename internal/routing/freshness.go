@@ -0,0 +1,77 @@
+package routing
+
+import (
+	"context"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/quota"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+// isStale reports that cached thumbnail is older than cache TTL
+func (t *ThumbnailFetchService) isStale(thumb *proto.Thumbnail) bool {
+	if t.cacheCfg == nil || t.cacheCfg.TTL == 0 {
+		return false
+	}
+	return time.Since(time.Unix(thumb.GetFetchedAt(), 0)) > t.cacheCfg.TTL
+}
+
+// revalidate filters stale cached responses.
+// In stale-while-revalidate mode stale responses are kept and queued for background refresh,
+// otherwise they are dropped to be downloaded again.
+// Background refresh is paused past soft limit of quota, stale responses are kept past hard limit.
+func (t *ThumbnailFetchService) revalidate(responses ...*proto.ThumbnailResponse) []*proto.ThumbnailResponse {
+	var (
+		served = make([]*proto.ThumbnailResponse, 0, len(responses))
+		stale  []utils.Variant
+
+		// Quota level is read once stale thumbnail is met
+		level   quota.Level
+		checked bool
+	)
+
+	for _, resp := range responses {
+		thumb := resp.GetThumbnail()
+		if thumb == nil {
+			continue
+		}
+		if !t.isStale(thumb) {
+			served = append(served, resp)
+			continue
+		}
+
+		if !checked {
+			level, checked = t.apiClient.QuotaLevel(), true
+		}
+
+		// Past hard limit of quota stale thumbnail can't be downloaded again
+		if t.cacheCfg.StaleWhileRevalidate || level == quota.LevelHard {
+			served = append(served, resp)
+		}
+		// Past soft limit remaining quota is kept for client requests
+		if t.cacheCfg.StaleWhileRevalidate && level == quota.LevelNormal {
+			stale = append(stale, utils.Variant{
+				ID:         thumb.GetId(),
+				Resolution: thumb.GetResolution(),
+				Parts:      utils.NewParts(thumb.GetMetadata().GetParts()...),
+			})
+		}
+	}
+
+	if len(stale) != 0 {
+		t.cacheQ.PutRefresh(stale...)
+	}
+	return served
+}
+
+// refresh downloads stale videos again. It is a scheduler.Refresher.
+func (t *ThumbnailFetchService) refresh(ctx context.Context, variants ...utils.Variant) []*proto.Thumbnail {
+	thumbnails := make([]*proto.Thumbnail, 0, len(variants))
+	t.apiClient.StreamVideoThumbnail(ctx, func(_ utils.Variant, resp *proto.ThumbnailResponse, err error) {
+		if resp != nil {
+			thumbnails = append(thumbnails, resp.GetThumbnail())
+		}
+	}, variants...)
+	return thumbnails
+}
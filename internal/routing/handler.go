@@ -2,46 +2,110 @@ package routing
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
 	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
 	"github.com/fluxx1on/thumbnails_microservice/internal/cache"
 	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
 	"github.com/fluxx1on/thumbnails_microservice/internal/scheduler"
+	"github.com/fluxx1on/thumbnails_microservice/libs/quota"
+	"github.com/fluxx1on/thumbnails_microservice/libs/signing"
 	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
 	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/codes"
 )
 
 const (
-	ErrDownloadVideo = "Downloading failed; video no exist"
+	ErrDownloadVideo   = "Downloading failed; video no exist"
+	ErrPrivateVideo    = "Downloading failed; video is private"
+	ErrQuotaExceeded   = "Downloading failed; YouTube quota exceeded, only cached thumbnails are served"
+	ErrUpstreamTimeout = "Downloading failed; YouTube timeout"
+	ErrUpstreamError   = "Downloading failed; YouTube error"
+	ErrCacheError      = "Reading failed; cache is unavailable"
+	ErrNothing         = "nothing to response"
+)
+
+// downloadMessages are messages of ErrorResponse by code
+var downloadMessages = map[proto.ErrorCode]string{
+	proto.ErrorCode_NOT_FOUND:        ErrDownloadVideo,
+	proto.ErrorCode_PRIVATE:          ErrPrivateVideo,
+	proto.ErrorCode_QUOTA_EXCEEDED:   ErrQuotaExceeded,
+	proto.ErrorCode_UPSTREAM_TIMEOUT: ErrUpstreamTimeout,
+	proto.ErrorCode_UPSTREAM_ERROR:   ErrUpstreamError,
+}
+
+// failureResponse is an ErrorResponse of failed YouTube download
+func failureResponse(videoID string, err error) *proto.ThumbnailResponse {
+	code := youtube.ErrorCode(err)
+	msg, ok := downloadMessages[code]
+	if !ok {
+		msg = ErrUpstreamError
+	}
+	return utils.NewErrorThumbnailResponse(videoID, code, msg)
+}
+
+var (
+	curDir = "/internal/routing"
 )
 
 type ThumbnailFetcher interface {
 	FetchThumbnail(context.Context, *proto.GetThumbnailRequest) (*proto.ThumbnailResponse, error)
 	FetchThumbnailList(context.Context, *proto.ListThumbnailRequest) ([]*proto.ThumbnailResponse, error)
+	StreamThumbnailList(context.Context, *proto.ListThumbnailRequest, func(*proto.ThumbnailResponse) error) error
+}
+
+// Upstream downloads thumbnails missing in cache
+type Upstream interface {
+	StreamVideoThumbnail(context.Context, youtube.ResolveFunc, ...utils.Variant)
+	QuotaLevel() quota.Level
 }
 
+var _ Upstream = (*youtube.APIClient)(nil)
+
 var _ ThumbnailFetcher = (*ThumbnailFetchService)(nil)
 
 type ThumbnailFetchService struct {
 	cacheQ    *scheduler.CacheQueue
-	apiClient *youtube.APIClient
+	apiClient Upstream
+	cacheCfg  *config.CacheBackend
+
+	// urlSigner links images of SIGNED_URL delivery. Nil disables it.
+	urlSigner *signing.URLSigner
+
+	// flights coalesces concurrent downloads of the same video
+	flights *Coalescer
 }
 
 func NewThumbnailFetchService(cache *scheduler.CacheQueue,
-	apiClient *youtube.APIClient) *ThumbnailFetchService {
+	apiClient Upstream, cacheCfg *config.CacheBackend) *ThumbnailFetchService {
 
-	return &ThumbnailFetchService{
+	t := &ThumbnailFetchService{
 		cacheQ:    cache,
 		apiClient: apiClient,
+		cacheCfg:  cacheCfg,
+		flights:   NewCoalescer(),
 	}
+	cache.SetRefresher(t.refresh)
+
+	return t
+}
+
+// SetURLSigner enables SIGNED_URL delivery
+func (t *ThumbnailFetchService) SetURLSigner(signer *signing.URLSigner) {
+	t.urlSigner = signer
 }
 
-func (t *ThumbnailFetchService) getCacheClient() *cache.RedisQuery {
+// CoalesceStats returns statistic of coalesced downloads
+func (t *ThumbnailFetchService) CoalesceStats() CoalesceStats {
+	return t.flights.Stats()
+}
+
+func (t *ThumbnailFetchService) getCacheClient() cache.Cache {
 	return t.cacheQ.GetCacheClient()
 }
 
-// cacheProducer is a producer for CacheQueue
+// cacheProducer is a producer for CacheQueue.
+// Meta data without image isn't cached.
 func (t *ThumbnailFetchService) cacheProducer(ctx context.Context, videoList ...*proto.ThumbnailResponse) {
 	var thumbnailList = make([]*proto.Thumbnail, 0, len(videoList))
 
@@ -51,54 +115,49 @@ func (t *ThumbnailFetchService) cacheProducer(ctx context.Context, videoList ...
 			slog.Warn("Try to caching requested with errors")
 			return
 		}
-		thumbnailList = append(thumbnailList, resp.GetThumbnail())
+		if thumb := resp.GetThumbnail(); len(thumb.GetFile()) != 0 {
+			thumbnailList = append(thumbnailList, thumb)
+		}
+	}
+	if len(thumbnailList) == 0 {
+		return
 	}
 
 	t.cacheQ.PutQueue(thumbnailList...)
 }
 
-// FetchThumbnailList is intermediate node that gather all Thumbnails from cache or API
-func (t *ThumbnailFetchService) FetchThumbnailList(ctx context.Context, reqList *proto.ListThumbnailRequest) (
-	[]*proto.ThumbnailResponse, error) {
-	var (
-		thumbResponse = make([]*proto.ThumbnailResponse, 0, len(reqList.GetRequests()))
-		cacheListID   = make([]string, 0, len(reqList.GetRequests()))
-	)
-
-	// Validate requested URLs
-	// By Error append ErrorResponse
-	for _, value := range reqList.GetRequests() {
-		id, err := GetQueryID(value.GetUrl())
-		if err != nil {
-			thumbResponse = append(thumbResponse,
-				utils.NewErrorThumbnailResponse(value.GetUrl(), id))
-		} else {
-			cacheListID = append(cacheListID, id)
+// fetchUpstream downloads variants from YouTube and queues them for caching
+func (t *ThumbnailFetchService) fetchUpstream(ctx context.Context, resolved youtube.ResolveFunc,
+	variants ...utils.Variant) {
+	t.apiClient.StreamVideoThumbnail(ctx, func(variant utils.Variant, resp *proto.ThumbnailResponse, err error) {
+		if resp != nil {
+			// Try to caching
+			t.cacheProducer(ctx, resp)
 		}
-	}
-
-	// Append cached ThumbnailReponses from Redis and filesystem
-	cachedThumbnails, apiListID := t.getCacheClient().GetSeries(ctx, cacheListID...)
-	thumbResponse = append(thumbResponse, cachedThumbnails...)
+		resolved(variant, resp, err)
+	}, variants...)
+}
 
-	// Append ThumbnailResponses from youtube API
-	apiThumbnails, errListID := t.apiClient.GetVideoThumbnail(ctx, apiListID...)
-	if apiThumbnails != nil {
-		// Try to caching
-		t.cacheProducer(ctx, apiThumbnails...)
+// download gets variants from YouTube and passes every one of them to result as soon as it is resolved.
+// Concurrent requests of the same video share one download.
+func (t *ThumbnailFetchService) download(ctx context.Context, result ResultFunc, variants ...utils.Variant) error {
+	return t.flights.Do(ctx, t.fetchUpstream, result, variants...)
+}
 
-		thumbResponse = append(thumbResponse, apiThumbnails...)
-	}
+// FetchThumbnailList is intermediate node that gather all Thumbnails from cache or API.
+// Responses are kept in order of requests.
+func (t *ThumbnailFetchService) FetchThumbnailList(ctx context.Context, reqList *proto.ListThumbnailRequest) (
+	[]*proto.ThumbnailResponse, error) {
+	thumbResponse := make([]*proto.ThumbnailResponse, len(reqList.GetRequests()))
 
-	// Incorrect Video IDs; Append ErrorResponses
-	for _, url := range errListID {
-		thumbResponse = append(thumbResponse,
-			utils.NewErrorThumbnailResponse(url, ErrDownloadVideo))
+	err := t.StreamThumbnailList(ctx, reqList, func(resp *proto.ThumbnailResponse) error {
+		thumbResponse[resp.GetRequestIndex()] = resp
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(thumbResponse) == 0 {
-		return nil, fmt.Errorf("nothing to response")
-	}
 	return thumbResponse, nil
 }
 
@@ -106,32 +165,55 @@ func (t *ThumbnailFetchService) FetchThumbnailList(ctx context.Context, reqList
 func (t *ThumbnailFetchService) FetchThumbnail(ctx context.Context, req *proto.GetThumbnailRequest) (
 	*proto.ThumbnailResponse, error) {
 
+	resp, err := t.fetchThumbnail(ctx, req)
+	if resp != nil {
+		resp.RequestedUrl = req.GetUrl()
+	}
+	return resp, err
+}
+
+func (t *ThumbnailFetchService) fetchThumbnail(ctx context.Context, req *proto.GetThumbnailRequest) (
+	*proto.ThumbnailResponse, error) {
+
 	// It gets video ID
 	// Return ErrorResponse by incorrect url query parameters
 	id, err := GetQueryID(req.GetUrl())
 	if err != nil {
-		return utils.NewErrorThumbnailResponse(req.GetUrl(), id), nil
+		return utils.NewErrorThumbnailResponse(req.GetUrl(), proto.ErrorCode_INVALID_URL, id), nil
+	}
+
+	variant, invalid := t.newVariant(id, req)
+	if invalid != nil {
+		return invalid, nil
 	}
 
 	// Return Cached response
-	cachedThumbnail := t.getCacheClient().Get(ctx, id)
-	if cachedThumbnail != nil {
-		return cachedThumbnail, nil
+	// Unavailable cache isn't bypassed, YouTube quota would be spent on every cached video
+	cachedThumbnail, err := t.getCacheClient().Get(ctx, variant)
+	if err != nil {
+		return utils.NewErrorThumbnailResponse(id, proto.ErrorCode_CACHE_ERROR, ErrCacheError), nil
+	}
+	if served := t.revalidate(cachedThumbnail); len(served) != 0 {
+		return t.respond(served[0], variant), nil
 	}
 
 	// Return ThumbnailResponse from youtube API
-	apiThumbnail, errListID := t.apiClient.GetVideoThumbnail(ctx, id)
+	var (
+		apiThumbnail *proto.ThumbnailResponse
+		apiErr       error
+	)
+	t.download(ctx, func(_ utils.Variant, resp *proto.ThumbnailResponse, err error) error {
+		apiThumbnail, apiErr = resp, err
+		return nil
+	}, variant)
 	if apiThumbnail != nil {
-		// Try to caching
-		t.cacheProducer(ctx, apiThumbnail...)
-
-		return apiThumbnail[0], nil
+		return t.respond(apiThumbnail, variant), nil
 	}
 
 	// Nothing finded; Return ErrorResponse
-	if errListID != nil {
-		return utils.NewErrorThumbnailResponse(id, ErrDownloadVideo), nil
+	if apiErr != nil {
+		return failureResponse(id, apiErr), nil
 	}
 
-	return nil, fmt.Errorf("nothing to response")
+	return nil, utils.NewStatusError(codes.Internal, proto.ErrorCode_UPSTREAM_ERROR, ErrNothing)
 }
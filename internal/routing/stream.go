@@ -0,0 +1,130 @@
+package routing
+
+import (
+	"context"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+	"google.golang.org/grpc/codes"
+)
+
+// slot is a valid request waiting for response
+type slot struct {
+	index   int
+	variant utils.Variant
+}
+
+func slotVariants(slots []slot) []utils.Variant {
+	variants := make([]utils.Variant, 0, len(slots))
+	for _, s := range slots {
+		variants = append(variants, s.variant)
+	}
+	return variants
+}
+
+// emitMatched sends responses to slots they answer.
+// It returns slots that are still waiting for response.
+func (t *ThumbnailFetchService) emitMatched(slots []slot, responses []*proto.ThumbnailResponse,
+	emit func(int, *proto.ThumbnailResponse) error) ([]slot, error) {
+	var (
+		variants = slotVariants(slots)
+		answered = make([]bool, len(slots))
+		pending  = make([]slot, 0, len(slots))
+	)
+
+	for r, i := range matchVariants(variants, responses) {
+		if i < 0 {
+			continue
+		}
+		answered[i] = true
+		if err := emit(slots[i].index, t.respond(responses[r], variants[i])); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, s := range slots {
+		if !answered[i] {
+			pending = append(pending, s)
+		}
+	}
+	return pending, nil
+}
+
+// StreamThumbnailList resolves requests from cache, then from API,
+// and sends every response as soon as it is resolved.
+// Response keeps index and url of request in ListThumbnailRequest.
+func (t *ThumbnailFetchService) StreamThumbnailList(ctx context.Context, reqList *proto.ListThumbnailRequest,
+	send func(*proto.ThumbnailResponse) error) error {
+	var (
+		requests = reqList.GetRequests()
+		pending  = make([]slot, 0, len(requests))
+		err      error
+	)
+
+	if len(requests) == 0 {
+		return utils.NewStatusError(codes.InvalidArgument, proto.ErrorCode_INVALID_REQUEST, ErrNothing)
+	}
+
+	emit := func(index int, resp *proto.ThumbnailResponse) error {
+		resp.RequestIndex = int32(index)
+		resp.RequestedUrl = requests[index].GetUrl()
+		return send(resp)
+	}
+
+	// Validate requested URLs
+	// By Error send ErrorResponse
+	for index, value := range requests {
+		id, err := GetQueryID(value.GetUrl())
+		if err != nil {
+			err = emit(index, utils.NewErrorThumbnailResponse(value.GetUrl(), proto.ErrorCode_INVALID_URL, id))
+		} else if variant, invalid := t.newVariant(id, value); invalid != nil {
+			err = emit(index, invalid)
+		} else {
+			pending = append(pending, slot{index: index, variant: variant})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Send cached ThumbnailReponses from Redis and filesystem
+	cachedThumbnails, _, cacheErr := t.getCacheClient().GetSeries(ctx, slotVariants(pending)...)
+	cachedThumbnails = t.revalidate(cachedThumbnails...)
+	if pending, err = t.emitMatched(pending, cachedThumbnails, emit); err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Unavailable cache isn't bypassed, YouTube quota would be spent on every cached video
+	if cacheErr != nil {
+		for _, s := range pending {
+			resp := utils.NewErrorThumbnailResponse(s.variant.ID, proto.ErrorCode_CACHE_ERROR, ErrCacheError)
+			if err := emit(s.index, resp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Send ThumbnailResponses from youtube API as soon as every of them is resolved
+	// Incorrect Video IDs get ErrorResponses
+	slots := make(map[utils.Variant][]slot, len(pending))
+	for _, s := range pending {
+		slots[s.variant] = append(slots[s.variant], s)
+	}
+
+	return t.download(ctx, func(variant utils.Variant, resp *proto.ThumbnailResponse, err error) error {
+		s := slots[variant][0]
+		slots[variant] = slots[variant][1:]
+
+		if resp != nil {
+			return emit(s.index, t.respond(resp, variant))
+		}
+		return emit(s.index, failureResponse(variant.ID, err))
+	}, slotVariants(pending)...)
+}
@@ -1,8 +1,9 @@
 package routing
 
 import (
-	"fmt"
+	"errors"
 	"net/url"
+	"regexp"
 	"strings"
 )
 
@@ -10,21 +11,128 @@ const (
 	ErrInvalidURL = "Invalid video URL"
 )
 
+var (
+	// ErrMalformedURL means that source can't be parsed as URL at all
+	ErrMalformedURL = errors.New("malformed url")
+	// ErrUnsupportedHost means that URL host isn't a YouTube host
+	ErrUnsupportedHost = errors.New("unsupported host")
+	// ErrMissingID means that URL path or query doesn't carry a video ID
+	ErrMissingID = errors.New("video id not found")
+	// ErrInvalidID means that video ID has wrong syntax
+	ErrInvalidID = errors.New("invalid video id")
+)
+
+// URLError is returned by GetQueryID and ParseVideoURL.
+// Err is one of ErrMalformedURL, ErrUnsupportedHost, ErrMissingID, ErrInvalidID.
+type URLError struct {
+	URL string
+	Err error
+}
+
+func (e *URLError) Error() string {
+	return ErrInvalidURL + " " + `"` + e.URL + `": ` + e.Err.Error()
+}
+
+func (e *URLError) Unwrap() error {
+	return e.Err
+}
+
+// videoIDPattern is a syntax of YouTube video ID
+var videoIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// hostsAllowed is an allowlist of hosts serving YouTube videos.
+// Value reports that host is the short link host (youtu.be/<id>).
+var hostsAllowed = map[string]bool{
+	"youtube.com":              false,
+	"www.youtube.com":          false,
+	"m.youtube.com":            false,
+	"music.youtube.com":        false,
+	"youtube-nocookie.com":     false,
+	"www.youtube-nocookie.com": false,
+	"youtu.be":                 true,
+	"www.youtu.be":             true,
+}
+
+// pathPrefixes are path forms that keep video ID as the next path segment
+var pathPrefixes = []string{"shorts", "embed", "live", "v", "e"}
+
+// IsVideoID checks video ID syntax
+func IsVideoID(id string) bool {
+	return videoIDPattern.MatchString(id)
+}
+
+// ParseVideoURL recognizes every YouTube URL shape and returns video ID:
+//
+//	https://www.youtube.com/watch?v=<id>
+//	https://youtu.be/<id>
+//	https://www.youtube.com/{shorts,embed,live,v,e}/<id>
+//	m.youtube.com, music.youtube.com, youtube-nocookie.com hosts
+//	<id> (bare 11-characters ID)
+//
+// Scheme may be omitted. Returned error is always *URLError.
+func ParseVideoURL(src string) (string, error) {
+	src = strings.TrimSpace(src)
+
+	// Bare video ID
+	if IsVideoID(src) {
+		return src, nil
+	}
+
+	raw := src
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", &URLError{URL: src, Err: ErrMalformedURL}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", &URLError{URL: src, Err: ErrMalformedURL}
+	}
+
+	shortLink, allowed := hostsAllowed[strings.ToLower(u.Hostname())]
+	if !allowed {
+		return "", &URLError{URL: src, Err: ErrUnsupportedHost}
+	}
+
+	var (
+		id       string
+		segments = strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	)
+
+	switch {
+	case shortLink:
+		if len(segments) > 0 {
+			id = segments[0]
+		}
+	case len(segments) == 1 && segments[0] == "watch":
+		id = u.Query().Get("v")
+	case len(segments) >= 2:
+		for _, prefix := range pathPrefixes {
+			if segments[0] == prefix {
+				id = segments[1]
+				break
+			}
+		}
+	}
+
+	if strings.TrimSpace(id) == "" {
+		return "", &URLError{URL: src, Err: ErrMissingID}
+	}
+	if !IsVideoID(id) {
+		return "", &URLError{URL: src, Err: ErrInvalidID}
+	}
+	return id, nil
+}
+
 // GetQueryID is cutting videoID from URL.
 // Also GetQueryId validate youtube video URL.
+// By error it returns ErrInvalidURL message and *URLError.
 func GetQueryID(src string) (string, error) {
-	url, err := url.Parse(src)
-	// source is not a URL
+	id, err := ParseVideoURL(src)
 	if err != nil {
-		return ErrInvalidURL, fmt.Errorf("")
-	}
-
-	query := url.Query()
-	queryID := query.Get("v")
-
-	// query of params not contain a videoID (incorrect URL)
-	if strings.TrimSpace(queryID) == "" {
-		return ErrInvalidURL, fmt.Errorf("")
+		return ErrInvalidURL, err
 	}
-	return queryID, nil
+	return id, nil
 }
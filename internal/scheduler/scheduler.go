@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"sync"
 
 	"github.com/fluxx1on/thumbnails_microservice/internal/cache"
 	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
@@ -19,12 +20,23 @@ var (
 
 // var _ TaskQueue = (*CacheQueue)(nil)
 
+// Refresher downloads videos again to revalidate stale cache
+type Refresher func(context.Context, ...utils.Variant) []*proto.Thumbnail
+
 type CacheQueue struct {
 	cacheClient cache.Cache
 
 	// Queue is like task queue/schedule from broker
 	queue chan []*proto.Thumbnail
 
+	// refreshQueue keeps stale videos waiting for revalidation
+	refreshQueue chan utils.Variant
+	refresher    Refresher
+
+	// refreshing keeps keys of queued videos to not refresh them twice
+	refreshing   map[string]bool
+	refreshingMu sync.Mutex
+
 	// Context to stop broker queue before connection will be lost
 	// That make possible transact cache to redis without loss
 	ctx context.Context
@@ -32,27 +44,25 @@ type CacheQueue struct {
 	ctxCancel context.CancelFunc
 }
 
-func NewCacheQueue(ctx context.Context, cacheClient *cache.RedisQuery) *CacheQueue {
+func NewCacheQueue(ctx context.Context, cacheClient cache.Cache) *CacheQueue {
 	ctx, cancel := context.WithCancel(ctx)
 
 	return &CacheQueue{
-		cacheClient: cacheClient,
-		queue:       make(chan []*proto.Thumbnail, 100),
-		ctx:         ctx,
-		ctxCancel:   cancel,
+		cacheClient:  cacheClient,
+		queue:        make(chan []*proto.Thumbnail, 100),
+		refreshQueue: make(chan utils.Variant, 100),
+		refreshing:   make(map[string]bool),
+		ctx:          ctx,
+		ctxCancel:    cancel,
 	}
 }
 
-func (q *CacheQueue) GetCacheClient() *cache.RedisQuery {
-	if cli, is := q.cacheClient.(*cache.RedisQuery); is {
-		return cli
-	}
-	return nil
+func (q *CacheQueue) GetCacheClient() cache.Cache {
+	return q.cacheClient
 }
 
 // PutCache inspect that slice doesn't contain same video multiple times.
-// So after inspection putCache provide video meta data to redis
-// and thumbnail image to filesystem.
+// So after inspection putCache provide videos to cache backend.
 func (q *CacheQueue) PutCache(thumbResp []*proto.Thumbnail) {
 	var (
 		thumbList = make([]*proto.Thumbnail, 0, len(thumbResp))
@@ -60,25 +70,80 @@ func (q *CacheQueue) PutCache(thumbResp []*proto.Thumbnail) {
 	)
 
 	for index, thumb := range thumbResp {
-		dict[thumb.GetId()] = index
+		dict[utils.ThumbnailKey(thumb)] = index
 	}
 
-	for key, val := range dict {
-		if err := utils.WriteMediaFile(thumbResp[val].GetFile(), key); err != nil {
-			slog.Warn("Writing image file denied", err, curDir)
-		}
+	for _, val := range dict {
 		thumbList = append(thumbList, thumbResp[val])
 	}
 
 	q.GetCacheClient().SetSeries(q.ctx, thumbList...)
 
-	slog.Debug("All files were written succesfully")
+	slog.Debug("All videos were cached succesfully")
 }
 
 func (q *CacheQueue) PutQueue(thumb ...*proto.Thumbnail) {
 	q.queue <- thumb
 }
 
+// SetRefresher sets downloader used by refresh job
+func (q *CacheQueue) SetRefresher(refresher Refresher) {
+	q.refresher = refresher
+}
+
+// PutRefresh queues stale videos for revalidation.
+// Video already waiting for revalidation or overflowing queue is skipped.
+func (q *CacheQueue) PutRefresh(variants ...utils.Variant) {
+	q.refreshingMu.Lock()
+	defer q.refreshingMu.Unlock()
+
+	for _, variant := range variants {
+		key := variant.Key()
+		if q.refreshing[key] {
+			continue
+		}
+
+		select {
+		case q.refreshQueue <- variant:
+			q.refreshing[key] = true
+		default:
+			slog.Debug("Refresh queue is full", key, curDir)
+		}
+	}
+}
+
+// refresh downloads stale video and puts it to cache
+func (q *CacheQueue) refresh(variant utils.Variant) {
+	defer func() {
+		q.refreshingMu.Lock()
+		delete(q.refreshing, variant.Key())
+		q.refreshingMu.Unlock()
+	}()
+
+	if q.refresher == nil {
+		return
+	}
+
+	thumbnails := q.refresher(q.ctx, variant)
+	if len(thumbnails) != 0 {
+		q.PutCache(thumbnails)
+		slog.Debug("Stale video revalidated", variant.Key())
+	}
+}
+
+// RefreshRunning is One-Thread consumer of refresh queue.
+// Can be shutted down by context Cancelation.
+func (q *CacheQueue) RefreshRunning() {
+	for {
+		select {
+		case variant := <-q.refreshQueue:
+			q.refresh(variant)
+		case <-q.ctx.Done():
+			return
+		}
+	}
+}
+
 // JobRunning is One-Thread consumer.
 // It reads CacheQueue.queue and send it to CacheQueue.putCache.
 // Can be shutted down by context Cancelation.
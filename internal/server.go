@@ -3,19 +3,25 @@ package internal
 import (
 	"context"
 	"net"
+	"net/http"
 
 	"golang.org/x/exp/slog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/reflection"
 
 	// Current module
 	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
 	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
 	"github.com/fluxx1on/thumbnails_microservice/internal/cache"
+	"github.com/fluxx1on/thumbnails_microservice/internal/gateway"
 	igrpc "github.com/fluxx1on/thumbnails_microservice/internal/grpc"
 	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
 	"github.com/fluxx1on/thumbnails_microservice/internal/routing"
 	"github.com/fluxx1on/thumbnails_microservice/internal/scheduler"
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
+	"github.com/fluxx1on/thumbnails_microservice/libs/quota"
+	"github.com/fluxx1on/thumbnails_microservice/libs/signing"
 	"github.com/go-redis/redis"
 )
 
@@ -23,8 +29,80 @@ type GRPC struct {
 	listener  net.Listener
 	server    *grpc.Server
 	scheduler *scheduler.CacheQueue
+	cache     cache.Cache
+
+	fetchService *routing.ThumbnailFetchService
+	apiClient    *youtube.APIClient
+
+	httpServer *http.Server
+	health     *health.Server
+
+	// stopMedia cancels media maintenance of redis backed caches
+	stopMedia context.CancelFunc
+}
+
+func newMemoryCache(cfg *config.CacheBackend) *cache.MemoryCache {
+	memoryCache := cache.NewMemoryCache(cfg.MemoryEntries, cfg.MemoryBytes)
+	memoryCache.SetMaxAge(cfg.MaxAge())
+	return memoryCache
 }
 
+func newRedisQuery(cfg *config.CacheBackend, RedisConn *redis.Client) *cache.RedisQuery {
+	redisQuery := cache.NewRedisQuery(context.Background(), RedisConn)
+	redisQuery.TTL = cfg.MaxAge()
+	return redisQuery
+}
+
+// setupMediaStore selects store of thumbnail images
+func setupMediaStore(cfg *config.MediaStorage) {
+	if cfg.Store != "s3" {
+		return
+	}
+
+	store, err := blobstore.NewS3Store(blobstore.S3Config{
+		Endpoint:  cfg.S3.Endpoint,
+		Region:    cfg.S3.Region,
+		Bucket:    cfg.S3.Bucket,
+		AccessKey: cfg.S3.AccessKey,
+		SecretKey: cfg.S3.SecretKey,
+		PathStyle: cfg.S3.PathStyle,
+	})
+	if err != nil {
+		panic(err)
+	}
+	blobstore.Media = store
+}
+
+// startMedia collects unreferenced blobs of redis cache
+// and keeps media directory of filesystem store within budget.
+// Nil redisQuery means that blobs aren't referenced by cache.
+func (g *GRPC) startMedia(cfg *config.MediaStorage, redisQuery *cache.RedisQuery) {
+	var (
+		ctx     context.Context
+		onEvict func(digests ...string)
+	)
+	ctx, g.stopMedia = context.WithCancel(context.Background())
+
+	if redisQuery != nil {
+		go redisQuery.CollectingGarbage(ctx, cfg.GCInterval, cfg.GCGrace)
+		onEvict = redisQuery.ForgetMedia
+	}
+
+	fileStore, ok := blobstore.Media.(*blobstore.FileStore)
+	if !ok || (cfg.MaxBytes <= 0 && cfg.MaxFiles <= 0) {
+		return
+	}
+
+	sweeper := blobstore.NewSweeper(fileStore, blobstore.Budget{
+		MaxBytes: cfg.MaxBytes,
+		MaxFiles: cfg.MaxFiles,
+		Policy:   cfg.Eviction,
+		Interval: cfg.SweepInterval,
+	}, onEvict)
+	go sweeper.Running(ctx)
+}
+
+// StartUp runs gRPC server. RedisConn is used by redis cache backend only.
 func (g *GRPC) StartUp(cfg *config.Config, RedisConn *redis.Client) {
 	// Listener starting
 	var err error
@@ -38,21 +116,60 @@ func (g *GRPC) StartUp(cfg *config.Config, RedisConn *redis.Client) {
 	// gRPC creating
 	g.server = grpc.NewServer()
 	reflection.Register(g.server)
+	g.registerHealth()
+
+	// Media store setup
+	setupMediaStore(cfg.Media)
+	slog.Info("Media store:", cfg.Media.Store)
 
-	// RedisQuery caching setup
-	CacheClient := cache.NewRedisQuery(context.Background(), RedisConn)
+	// Cache backend setup
+	// Quota usage is shared by replicas through Redis
+	var (
+		CacheClient cache.Cache
+		quotaStore  quota.Store
+	)
+	switch cfg.Cache.Backend {
+	case cache.BackendMemory:
+		// Media store keeps linked images only
+		g.startMedia(cfg.Media, nil)
+		CacheClient = newMemoryCache(cfg.Cache)
+		quotaStore = quota.NewMemoryStore()
+	case cache.BackendTiered:
+		redisQuery := newRedisQuery(cfg.Cache, RedisConn)
+		g.startMedia(cfg.Media, redisQuery)
+		CacheClient = cache.NewTieredCache(newMemoryCache(cfg.Cache), redisQuery)
+		quotaStore = quota.NewRedisStore(RedisConn)
+	default:
+		redisQuery := newRedisQuery(cfg.Cache, RedisConn)
+		g.startMedia(cfg.Media, redisQuery)
+		CacheClient = redisQuery
+		quotaStore = quota.NewRedisStore(RedisConn)
+	}
+	slog.Info("Cache backend:", cfg.Cache.Backend)
+	g.cache = CacheClient
 
 	// Scheduler setup
 	CacheScheduler := scheduler.NewCacheQueue(context.Background(), CacheClient)
 	g.scheduler = CacheScheduler
 
 	// GRPCThumbnailService setup
+	youtube.SetupTransport(cfg.YouTube)
 	uAPI := youtube.NewAPIClient(cfg.YouTube) // YouTubeAPI init
-	srv := igrpc.NewThumbnailService(routing.NewThumbnailFetchService(
-		CacheScheduler, uAPI,
-	))
+	g.apiClient = uAPI
+	uAPI.SetQuota(quota.NewTracker(quotaStore, cfg.YouTube.QuotaSoft, cfg.YouTube.QuotaHard))
+	fetchService := routing.NewThumbnailFetchService(CacheScheduler, uAPI, cfg.Cache)
+	g.fetchService = fetchService
+	srv := igrpc.NewThumbnailService(fetchService)
 	proto.RegisterThumbnailServiceServer(g.server, srv)
 
+	// Signed links of images
+	var urlSigner *signing.URLSigner
+	if cfg.HTTP.URLSecret != "" {
+		urlSigner = signing.NewURLSigner([]byte(cfg.HTTP.URLSecret), cfg.HTTP.PublicURL, cfg.HTTP.URLTTL)
+		fetchService.SetURLSigner(urlSigner)
+	}
+	g.startHTTP(cfg.HTTP, gateway.NewGateway(fetchService, cfg.Cache.TTL), urlSigner)
+
 	// Server starting
 	go func() {
 		if err := g.server.Serve(g.listener); err != nil {
@@ -60,16 +177,43 @@ func (g *GRPC) StartUp(cfg *config.Config, RedisConn *redis.Client) {
 		}
 	}()
 
-	// Start consumer
+	// Start consumers
 	go g.scheduler.JobRunning()
+	go g.scheduler.RefreshRunning()
 
 	slog.Info("gRPC server started on address:", cfg.ServerAddress)
 }
 
+// CacheStats returns lookup statistic of cache tiers if backend counts it
+func (g *GRPC) CacheStats() []cache.TierStats {
+	if reporter, ok := g.cache.(cache.StatsReporter); ok {
+		return reporter.Stats()
+	}
+	return nil
+}
+
 func (g *GRPC) Stop() {
 	if g.scheduler != nil {
 		g.scheduler.ShutdownJob()
 	}
+	if g.stopMedia != nil {
+		g.stopMedia()
+	}
+	for _, stats := range g.CacheStats() {
+		slog.Info("Cache tier stats", stats.String())
+	}
+	if g.fetchService != nil {
+		slog.Info("Download coalescing stats", g.fetchService.CoalesceStats().String())
+	}
+	if g.apiClient != nil {
+		for _, stats := range g.apiClient.KeyStats() {
+			slog.Info("API key stats", stats.String())
+		}
+	}
+	if g.health != nil {
+		g.health.Shutdown()
+	}
+	g.stopHTTP()
 	g.server.Stop()
 	g.listener.Close()
 }
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// StatsPath is a path of HTTP runtime statistic
+const StatsPath = "/stats"
+
+type tierReport struct {
+	Tier     string  `json:"tier"`
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+type coalesceReport struct {
+	Fetched   uint64  `json:"fetched"`
+	Coalesced uint64  `json:"coalesced"`
+	Ratio     float64 `json:"ratio"`
+}
+
+type keyReport struct {
+	Key       string `json:"key"`
+	Requests  uint64 `json:"requests"`
+	Failures  uint64 `json:"failures"`
+	QuotaUsed int64  `json:"quota_used"`
+	// QuarantinedUntil is empty if key is in use
+	QuarantinedUntil string `json:"quarantined_until,omitempty"`
+}
+
+type statsReport struct {
+	// Cache is empty unless backend counts lookups of its tiers
+	Cache    []tierReport   `json:"cache"`
+	Coalesce coalesceReport `json:"coalesce"`
+	// Keys are API keys named by prefix of their SHA-256
+	Keys []keyReport `json:"keys"`
+}
+
+// statsHandler reports statistic collected since start of server
+func (g *GRPC) statsHandler(w http.ResponseWriter, r *http.Request) {
+	report := statsReport{Cache: make([]tierReport, 0), Keys: make([]keyReport, 0)}
+	for _, stats := range g.CacheStats() {
+		report.Cache = append(report.Cache, tierReport{
+			Tier:     stats.Tier,
+			Hits:     stats.Hits,
+			Misses:   stats.Misses,
+			HitRatio: stats.HitRatio(),
+		})
+	}
+
+	if g.fetchService != nil {
+		stats := g.fetchService.CoalesceStats()
+		report.Coalesce = coalesceReport{
+			Fetched:   stats.Fetched,
+			Coalesced: stats.Coalesced,
+			Ratio:     stats.Ratio(),
+		}
+	}
+
+	if g.apiClient != nil {
+		for _, stats := range g.apiClient.KeyStats() {
+			key := keyReport{
+				Key:       stats.Key,
+				Requests:  stats.Requests,
+				Failures:  stats.Failures,
+				QuotaUsed: stats.QuotaUsed,
+			}
+			if !stats.QuarantinedUntil.IsZero() {
+				key.QuarantinedUntil = stats.QuarantinedUntil.Format(time.RFC3339)
+			}
+			report.Keys = append(report.Keys, key)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(report)
+}
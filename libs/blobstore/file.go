@@ -0,0 +1,98 @@
+package blobstore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/exp/slog"
+)
+
+// tempPattern names unfinished writes in media directory
+const tempPattern = ".tmp-*"
+
+func readFile(path string) []byte {
+	file, err := os.Open(path)
+	if err != nil {
+		slog.Debug("nothing to read; file not exist", curDir)
+		return nil
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		slog.Warn("reading closed", err, curDir)
+	}
+	mediaAccess.touch(path)
+	return data
+}
+
+// writeFile writes image to temporary file and renames it into place,
+// so readers never see partially written image. Directory is synced after rename.
+func writeFile(imageData []byte, path string) error {
+
+	// Creating directory if no exist
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("directory unreached: %w", err)
+	}
+
+	// Temporary file is created in the same directory to keep rename atomic
+	file, err := os.CreateTemp(dir, tempPattern)
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath) // no-op after successful rename
+
+	// Write image bytes to file
+	length, err := file.Write(imageData)
+	if err == nil && length != len(imageData) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("file didn't write correctly: %d / %d: %w", length, len(imageData), err)
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("file didn't sync: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tempPath, 0644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes directory entries, so renamed file survives crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("directory didn't sync: %w", err)
+	}
+	return nil
+}
+
+// removeFile removes file and forgets its accesses
+func removeFile(path string) error {
+	mediaAccess.forget(path)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package blobstore
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+var _ MediaStore = (*S3Store)(nil)
+
+// S3Config describes bucket of S3-compatible object storage
+type S3Config struct {
+	// Endpoint is a base URL of storage, e.g. http://localhost:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses bucket by path instead of subdomain. MinIO needs it.
+	PathStyle bool
+}
+
+// S3Store keeps blobs in S3-compatible bucket, so it can be shared by replicas
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	signer   *Signer
+	client   *http.Client
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is empty")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	return &S3Store{
+		cfg:      cfg,
+		endpoint: endpoint,
+		signer: &Signer{
+			AccessKey: cfg.AccessKey,
+			SecretKey: cfg.SecretKey,
+			Region:    cfg.Region,
+			Service:   "s3",
+		},
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// objectURL returns URL of object key, bucket listing if key is empty
+func (s *S3Store) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	if s.cfg.PathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	}
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// do sends signed request with header. Response body is closed if error is returned.
+func (s *S3Store) do(method, key string, query url.Values, header http.Header, body []byte) (
+	*http.Response, error) {
+	payloadHash := EmptyPayloadHash
+	if body != nil {
+		payloadHash = Digest(body)
+	}
+
+	req, err := http.NewRequest(method, s.objectURL(key, query).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.signer.Sign(req, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("s3 %s %s: status code %d: %s", method, key, resp.StatusCode, message)
+	}
+	return resp, nil
+}
+
+// getObject reads object. Nil if object doesn't exist.
+func (s *S3Store) getObject(key string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (s *S3Store) putObject(key string, data []byte) error {
+	resp, err := s.do(http.MethodPut, key, nil, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// touchObject copies object onto itself, which refreshes its LastModified
+func (s *S3Store) touchObject(key string) error {
+	header := http.Header{
+		"Content-Type":             {"application/octet-stream"},
+		"X-Amz-Copy-Source":        {uriEncode("/"+s.cfg.Bucket+"/"+key, true)},
+		"X-Amz-Metadata-Directive": {"REPLACE"},
+	}
+	resp, err := s.do(http.MethodPut, key, nil, header, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("s3 copy %s: object is missing", key)
+	}
+	return nil
+}
+
+func (s *S3Store) deleteObject(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Store) hasObject(key string) (bool, error) {
+	resp, err := s.do(http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode != http.StatusNotFound, nil
+}
+
+// s3Object is an object of ListObjectsV2 response
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	Size         int64     `xml:"Size"`
+}
+
+type listBucketResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+// listObjects lists every object with key prefix
+func (s *S3Store) listObjects(prefix string) ([]s3Object, error) {
+	var (
+		objects []s3Object
+		token   string
+	)
+
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3 listing decoding: %w", err)
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Store) Put(data []byte) (string, error) {
+	digest := Digest(data)
+	key := shardKey(blobsDir, digest, "")
+
+	// Fresh LastModified protects blob from collection until it's referenced.
+	// Blob is uploaded again if it can't be refreshed.
+	if exists, err := s.hasObject(key); err == nil && exists {
+		if err := s.touchObject(key); err == nil {
+			return digest, nil
+		}
+	}
+	return digest, s.putObject(key, data)
+}
+
+// Get reads blob and checks it against digest. Corrupted blob is removed.
+func (s *S3Store) Get(digest string) []byte {
+	if !validDigest(digest) {
+		return nil
+	}
+
+	data, err := s.getObject(shardKey(blobsDir, digest, ""))
+	if err != nil {
+		slog.Warn("Blob reading failed", err, curDir)
+		return nil
+	}
+	if data == nil || Digest(data) == digest {
+		return data
+	}
+
+	slog.Warn("Blob checksum mismatch", digest, curDir)
+	if err := s.Remove(digest); err != nil {
+		slog.Warn("Removing corrupted blob denied", err, curDir)
+	}
+	return nil
+}
+
+func (s *S3Store) Remove(digest string) error {
+	derived, err := s.listObjects(shardKey(derivedDir, digest, ""))
+	if err != nil {
+		return err
+	}
+	for _, object := range derived {
+		if err := s.deleteObject(object.Key); err != nil {
+			return err
+		}
+	}
+	return s.deleteObject(shardKey(blobsDir, digest, ""))
+}
+
+func (s *S3Store) GetDerived(digest, suffix string) []byte {
+	data, err := s.getObject(shardKey(derivedDir, digest, suffix))
+	if err != nil {
+		slog.Warn("Derivative reading failed", err, curDir)
+		return nil
+	}
+	return data
+}
+
+func (s *S3Store) PutDerived(data []byte, digest, suffix string) error {
+	return s.putObject(shardKey(derivedDir, digest, suffix), data)
+}
+
+// Collect removes unreferenced blobs which aren't modified longer than grace
+func (s *S3Store) Collect(grace time.Duration, unreferenced func(digests ...string) []string) []string {
+	objects, err := s.listObjects(blobsDir + "/")
+	if err != nil {
+		slog.Warn("Blobs listing failed", err, curDir)
+		return nil
+	}
+
+	var candidates []string
+	for _, object := range objects {
+		digest := object.Key[strings.LastIndex(object.Key, "/")+1:]
+		if validDigest(digest) && time.Since(object.LastModified) >= grace {
+			candidates = append(candidates, digest)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var removed []string
+	for _, digest := range unreferenced(candidates...) {
+		if err := s.Remove(digest); err != nil {
+			slog.Warn("Removing unreferenced blob denied", err, curDir)
+			continue
+		}
+		removed = append(removed, digest)
+	}
+
+	if len(removed) != 0 {
+		slog.Debug("Unreferenced blobs collected", len(removed), curDir)
+	}
+	return removed
+}
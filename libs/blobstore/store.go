@@ -0,0 +1,205 @@
+package blobstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	curDir = "/libs/blobstore"
+
+	blobsDir   = "blobs"
+	derivedDir = "derived"
+)
+
+var rootDir, _ = os.Getwd()
+
+// Media is a store of thumbnail images. Filesystem store by default.
+var Media MediaStore = NewFileStore(filepath.Join(rootDir, "media"))
+
+// MediaStore is a content-addressed storage of images.
+// Blob is named by SHA-256 of its content, so identical images are kept once.
+// Derivatives of blob are stored by digest of original blob and suffix.
+type MediaStore interface {
+	// Put stores image and returns its digest. Existing blob isn't rewritten.
+	Put(data []byte) (string, error)
+	// Get reads blob and checks it against digest.
+	// Missing or corrupted blob is nil.
+	Get(digest string) []byte
+	// Remove removes blob and its derivatives
+	Remove(digest string) error
+
+	// PutDerived stores resized or transcoded image of blob
+	PutDerived(data []byte, digest, suffix string) error
+	// GetDerived reads resized or transcoded image of blob
+	GetDerived(digest, suffix string) []byte
+
+	// Collect removes blobs older than grace which are reported by unreferenced.
+	// Grace protects blobs which are written, but aren't referenced yet.
+	// It returns digests of removed blobs.
+	Collect(grace time.Duration, unreferenced func(digests ...string) []string) []string
+}
+
+var _ MediaStore = (*FileStore)(nil)
+
+// FileStore keeps blobs in local directory sharded by the first bytes of digest
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Digest returns hex encoded SHA-256 of image
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// shardKey shards blobs by the first bytes of digest
+func shardKey(kind, digest, suffix string) string {
+	return kind + "/" + digest[:2] + "/" + digest[2:4] + "/" + digest + suffix
+}
+
+// validDigest reports that digest is hex encoded SHA-256
+func validDigest(digest string) bool {
+	if len(digest) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(digest)
+	return err == nil
+}
+
+// ParseName splits name of blob or derivative into digest and suffix
+func ParseName(name string) (string, string, bool) {
+	if len(name) < sha256.Size*2 || !validDigest(name[:sha256.Size*2]) {
+		return "", "", false
+	}
+
+	digest, suffix := name[:sha256.Size*2], name[sha256.Size*2:]
+	for _, c := range suffix {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return "", "", false
+		}
+	}
+	return digest, suffix, true
+}
+
+func (s *FileStore) shardPath(kind, digest, suffix string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(shardKey(kind, digest, suffix)))
+}
+
+// Path returns path of blob
+func (s *FileStore) Path(digest string) string {
+	return s.shardPath(blobsDir, digest, "")
+}
+
+// digestOf returns digest of blob stored at path.
+// False if path isn't a blob of store.
+func (s *FileStore) digestOf(path string) (string, bool) {
+	digest := filepath.Base(path)
+	if !validDigest(digest) || path != s.Path(digest) {
+		return "", false
+	}
+	return digest, true
+}
+
+func (s *FileStore) Put(data []byte) (string, error) {
+	digest := Digest(data)
+	path := s.Path(digest)
+	if _, err := os.Stat(path); err == nil {
+		// Fresh modification time protects blob from collection until it's referenced
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		return digest, nil
+	}
+	return digest, writeFile(data, path)
+}
+
+// Get reads blob and checks it against digest. Corrupted blob is removed.
+func (s *FileStore) Get(digest string) []byte {
+	if !validDigest(digest) {
+		return nil
+	}
+
+	path := s.Path(digest)
+	data := readFile(path)
+	if data == nil || Digest(data) == digest {
+		return data
+	}
+
+	slog.Warn("Blob checksum mismatch", digest, curDir)
+	if err := s.Remove(digest); err != nil {
+		slog.Warn("Removing corrupted blob denied", err, curDir)
+	}
+	return nil
+}
+
+func (s *FileStore) Remove(digest string) error {
+	derived, _ := filepath.Glob(s.shardPath(derivedDir, digest, "*"))
+	for _, path := range derived {
+		if err := removeFile(path); err != nil {
+			return err
+		}
+	}
+	return removeFile(s.Path(digest))
+}
+
+// DerivedPath returns path of resized or transcoded image of blob
+func (s *FileStore) DerivedPath(digest, suffix string) string {
+	return s.shardPath(derivedDir, digest, suffix)
+}
+
+func (s *FileStore) GetDerived(digest, suffix string) []byte {
+	return readFile(s.DerivedPath(digest, suffix))
+}
+
+func (s *FileStore) PutDerived(data []byte, digest, suffix string) error {
+	return writeFile(data, s.DerivedPath(digest, suffix))
+}
+
+// Collect removes unreferenced blobs which aren't accessed longer than grace
+func (s *FileStore) Collect(grace time.Duration, unreferenced func(digests ...string) []string) []string {
+	var candidates []string
+	mediaAccess.flush()
+
+	filepath.WalkDir(filepath.Join(s.dir, blobsDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || time.Since(mediaAccess.accessed(path, info.ModTime())) < grace {
+			return nil
+		}
+		if digest, ok := s.digestOf(path); ok {
+			candidates = append(candidates, digest)
+		}
+		return nil
+	})
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var removed []string
+	for _, digest := range unreferenced(candidates...) {
+		if err := s.Remove(digest); err != nil {
+			slog.Warn("Removing unreferenced blob denied", err, curDir)
+			continue
+		}
+		removed = append(removed, digest)
+	}
+
+	if len(removed) != 0 {
+		slog.Debug("Unreferenced blobs collected", len(removed), curDir)
+	}
+	return removed
+}
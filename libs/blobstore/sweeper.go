@@ -0,0 +1,243 @@
+package blobstore
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Eviction policies of Sweeper
+const (
+	EvictLRU = "lru"
+	EvictLFU = "lfu"
+)
+
+// accessCounter counts reads of media files for LFU eviction and keeps their access times.
+// Reads don't write to disk, access times are flushed to files in batches.
+type accessCounter struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+	// pending keeps access times not flushed yet
+	pending map[string]time.Time
+}
+
+var mediaAccess = &accessCounter{
+	counts:  make(map[string]uint64),
+	pending: make(map[string]time.Time),
+}
+
+// touch records file access
+func (a *accessCounter) touch(path string) {
+	a.mu.Lock()
+	a.counts[path]++
+	a.pending[path] = time.Now()
+	a.mu.Unlock()
+}
+
+func (a *accessCounter) get(path string) uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.counts[path]
+}
+
+// accessed returns access time of file modified at modTime
+func (a *accessCounter) accessed(path string, modTime time.Time) time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if at, ok := a.pending[path]; ok && at.After(modTime) {
+		return at
+	}
+	return modTime
+}
+
+// flush writes pending access times to files. Modification time is used as access time,
+// because filesystems are often mounted with noatime.
+// Files which no longer exist are forgotten, whoever removed them.
+func (a *accessCounter) flush() {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[string]time.Time)
+	paths := make([]string, 0, len(a.counts))
+	for path := range a.counts {
+		paths = append(paths, path)
+	}
+	a.mu.Unlock()
+
+	var gone []string
+	for _, path := range paths {
+		var err error
+		if at, ok := pending[path]; ok {
+			err = os.Chtimes(path, at, at)
+		} else {
+			_, err = os.Stat(path)
+		}
+		if os.IsNotExist(err) {
+			gone = append(gone, path)
+		}
+	}
+
+	a.mu.Lock()
+	for _, path := range gone {
+		delete(a.counts, path)
+	}
+	a.mu.Unlock()
+}
+
+func (a *accessCounter) forget(path string) {
+	a.mu.Lock()
+	delete(a.counts, path)
+	delete(a.pending, path)
+	a.mu.Unlock()
+}
+
+// Budget limits store directory. Zero limit means no limit.
+type Budget struct {
+	MaxBytes int64
+	MaxFiles int
+	// Policy is EvictLRU or EvictLFU
+	Policy string
+	// Interval is a period of sweeping
+	Interval time.Duration
+}
+
+// mediaFile is a file of store directory
+type mediaFile struct {
+	path     string
+	size     int64
+	accessed time.Time
+	hits     uint64
+}
+
+// Sweeper evicts blobs and derivatives over budget in background
+type Sweeper struct {
+	store   *FileStore
+	budget  Budget
+	onEvict func(digests ...string)
+}
+
+// NewSweeper creates sweeper of filesystem store. onEvict is called with digests of evicted blobs,
+// so cache entries pointing at them can be removed.
+func NewSweeper(store *FileStore, budget Budget, onEvict func(digests ...string)) *Sweeper {
+	if budget.Interval <= 0 {
+		budget.Interval = time.Minute
+	}
+
+	return &Sweeper{
+		store:   store,
+		budget:  budget,
+		onEvict: onEvict,
+	}
+}
+
+func (s *Sweeper) overBudget(bytes int64, files int) bool {
+	return (s.budget.MaxBytes > 0 && bytes > s.budget.MaxBytes) ||
+		(s.budget.MaxFiles > 0 && files > s.budget.MaxFiles)
+}
+
+// scan lists files of store and their total size
+func (s *Sweeper) scan() ([]mediaFile, int64) {
+	var (
+		files []mediaFile
+		total int64
+	)
+
+	filepath.WalkDir(s.store.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		// Unfinished writes are renamed into place later
+		if matched, _ := filepath.Match(tempPattern, d.Name()); matched {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		files = append(files, mediaFile{
+			path:     path,
+			size:     info.Size(),
+			accessed: mediaAccess.accessed(path, info.ModTime()),
+			hits:     mediaAccess.get(path),
+		})
+		total += info.Size()
+		return nil
+	})
+
+	return files, total
+}
+
+// Sweep flushes access times of media files and evicts files until store directory fits budget.
+// It returns paths of evicted files.
+func (s *Sweeper) Sweep() []string {
+	mediaAccess.flush()
+
+	files, total := s.scan()
+	if !s.overBudget(total, len(files)) {
+		return nil
+	}
+
+	// Files to evict first are at the beginning
+	sort.Slice(files, func(i, j int) bool {
+		if s.budget.Policy == EvictLFU && files[i].hits != files[j].hits {
+			return files[i].hits < files[j].hits
+		}
+		return files[i].accessed.Before(files[j].accessed)
+	})
+
+	var (
+		evicted []string
+		count   = len(files)
+	)
+	for _, file := range files {
+		if !s.overBudget(total, count) {
+			break
+		}
+		if err := removeFile(file.path); err != nil {
+			slog.Warn("Removing media file denied", err, curDir)
+			continue
+		}
+
+		evicted = append(evicted, file.path)
+		total -= file.size
+		count--
+	}
+
+	if len(evicted) != 0 {
+		if s.onEvict != nil {
+			var digests []string
+			for _, path := range evicted {
+				if digest, ok := s.store.digestOf(path); ok {
+					digests = append(digests, digest)
+				}
+			}
+			s.onEvict(digests...)
+		}
+		slog.Debug("Media files evicted", len(evicted), curDir)
+	}
+	return evicted
+}
+
+// Running sweeps store directory periodically.
+// Can be shutted down by context Cancelation.
+func (s *Sweeper) Running(ctx context.Context) {
+	ticker := time.NewTicker(s.budget.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Sweep()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // WebP decoder
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+)
+
+const (
+	MimeJPEG = "image/jpeg"
+	MimePNG  = "image/png"
+	MimeWebP = "image/webp"
+
+	jpegQuality = 85
+	maxSide     = 4096
+)
+
+var (
+	// ErrUnsupportedFormat means that there is no pure-Go encoder for format
+	ErrUnsupportedFormat = errors.New("output format isn't supported")
+	// ErrInvalidSize means that requested width or height is out of range
+	ErrInvalidSize = errors.New("invalid image size")
+)
+
+// Spec describes derivative of original thumbnail
+type Spec struct {
+	Width  int
+	Height int
+	Fit    proto.FitMode
+	Format proto.ImageFormat
+}
+
+// NewSpec builds Spec from request
+func NewSpec(req *proto.GetThumbnailRequest) Spec {
+	return Spec{
+		Width:  int(req.GetWidth()),
+		Height: int(req.GetHeight()),
+		Fit:    req.GetFit(),
+		Format: req.GetFormat(),
+	}
+}
+
+// IsOriginal reports that original image is requested
+func (s Spec) IsOriginal() bool {
+	return s.Width == 0 && s.Height == 0 && s.Format == proto.ImageFormat_ORIGINAL
+}
+
+// Validate checks requested size and format
+func (s Spec) Validate() error {
+	if s.Width < 0 || s.Height < 0 || s.Width > maxSide || s.Height > maxSide {
+		return fmt.Errorf("%w: %dx%d", ErrInvalidSize, s.Width, s.Height)
+	}
+	if s.Format == proto.ImageFormat_WEBP {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, s.Format)
+	}
+	return nil
+}
+
+// Suffix is a unique filename suffix of derivative
+func (s Spec) Suffix() string {
+	return fmt.Sprintf("_%dx%d_%s_%s", s.Width, s.Height, s.Fit, s.Format)
+}
+
+// Result describes produced image
+type Result struct {
+	Data     []byte
+	Width    int
+	Height   int
+	MimeType string
+}
+
+// Inspect reads size and MIME type of encoded image without full decoding
+func Inspect(data []byte) (Result, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Data:     data,
+		Width:    cfg.Width,
+		Height:   cfg.Height,
+		MimeType: http.DetectContentType(data),
+	}, nil
+}
+
+// Transform scales original image by Spec.Fit and encodes it in Spec.Format
+func Transform(data []byte, spec Spec) (Result, error) {
+	if err := spec.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("decoding failed: %w", err)
+	}
+
+	dst := resize(src, spec)
+
+	outFormat := spec.Format
+	if outFormat == proto.ImageFormat_ORIGINAL {
+		outFormat = proto.ImageFormat_JPEG
+		if format == "png" {
+			outFormat = proto.ImageFormat_PNG
+		}
+	}
+
+	var (
+		buf  bytes.Buffer
+		mime string
+	)
+	switch outFormat {
+	case proto.ImageFormat_JPEG:
+		mime = MimeJPEG
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+	case proto.ImageFormat_PNG:
+		mime = MimePNG
+		err = png.Encode(&buf, dst)
+	default:
+		return Result{}, fmt.Errorf("%w: %s", ErrUnsupportedFormat, outFormat)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("encoding failed: %w", err)
+	}
+
+	return Result{
+		Data:     buf.Bytes(),
+		Width:    dst.Bounds().Dx(),
+		Height:   dst.Bounds().Dy(),
+		MimeType: mime,
+	}, nil
+}
+
+// resize scales image into box of Spec. Zero side keeps aspect ratio.
+func resize(src image.Image, spec Spec) image.Image {
+	var (
+		bounds     = src.Bounds()
+		srcW, srcH = bounds.Dx(), bounds.Dy()
+		boxW, boxH = spec.Width, spec.Height
+	)
+
+	if srcW == 0 || srcH == 0 || (boxW == 0 && boxH == 0) {
+		return src
+	}
+
+	// One side given. Other is computed from aspect ratio.
+	switch {
+	case boxW == 0:
+		boxW = atLeastOne(srcW * boxH / srcH)
+	case boxH == 0:
+		boxH = atLeastOne(srcH * boxW / srcW)
+	}
+
+	var (
+		srcRect = bounds
+		dstW    = boxW
+		dstH    = boxH
+	)
+
+	switch spec.Fit {
+	case proto.FitMode_FIT_CONTAIN:
+		if srcW*boxH > srcH*boxW {
+			dstH = atLeastOne(srcH * boxW / srcW)
+		} else {
+			dstW = atLeastOne(srcW * boxH / srcH)
+		}
+	case proto.FitMode_FIT_COVER:
+		cropW, cropH := srcW, srcH
+		if srcW*boxH > srcH*boxW {
+			cropW = srcH * boxW / boxH
+		} else {
+			cropH = srcW * boxH / boxW
+		}
+		x0 := bounds.Min.X + (srcW-cropW)/2
+		y0 := bounds.Min.Y + (srcH-cropH)/2
+		srcRect = image.Rect(x0, y0, x0+cropW, y0+cropH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, draw.Src, nil)
+	return dst
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
@@ -0,0 +1,198 @@
+package quota
+
+import (
+	"errors"
+	"sync"
+	"time"
+	_ "time/tzdata" // Pacific time zone is needed on hosts without tzdata
+
+	"golang.org/x/exp/slog"
+)
+
+var (
+	curDir = "/libs/quota"
+
+	ErrExceeded = errors.New("daily quota exceeded")
+
+	// pacific is a time zone of YouTube Data API quota reset
+	pacific = loadPacific()
+)
+
+func loadPacific() *time.Location {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		return time.FixedZone("PST", -8*60*60)
+	}
+	return loc
+}
+
+// Day returns Pacific date of now, quota usage is reset by it
+func Day(now time.Time) string {
+	return now.In(pacific).Format("2006-01-02")
+}
+
+// Reset returns time when quota of day of now is reset
+func Reset(now time.Time) time.Time {
+	year, month, day := now.In(pacific).Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, pacific)
+}
+
+// Level is a state of quota usage
+type Level int
+
+const (
+	LevelNormal Level = iota
+	LevelSoft         // past soft limit, background requests are paused
+	LevelHard         // past hard limit, nothing is requested
+)
+
+// Store keeps usage of quota by day
+type Store interface {
+	// Add adds units to usage of day unless result exceeds limit. Zero limit means no limit.
+	// It returns usage of day and whether units are added.
+	Add(day string, units, limit int64) (int64, bool, error)
+	// Get returns usage of day
+	Get(day string) (int64, error)
+	// Raise sets usage of day to used if it is greater
+	Raise(day string, used int64) error
+}
+
+// Tracker charges API units to daily quota.
+// Zero limit means no limit.
+type Tracker struct {
+	store Store
+	soft  int64
+	hard  int64
+
+	// scope separates usage of trackers sharing store
+	scope string
+}
+
+func NewTracker(store Store, soft, hard int64) *Tracker {
+	return &Tracker{
+		store: store,
+		soft:  soft,
+		hard:  hard,
+	}
+}
+
+// Scoped returns tracker with the same limits and store counting usage of scope apart
+func (t *Tracker) Scoped(scope string) *Tracker {
+	return &Tracker{
+		store: t.store,
+		soft:  t.soft,
+		hard:  t.hard,
+		scope: scope,
+	}
+}
+
+// day returns store key of usage in day of now
+func (t *Tracker) day(now time.Time) string {
+	if t.scope == "" {
+		return Day(now)
+	}
+	return Day(now) + ":" + t.scope
+}
+
+// Charge spends units of quota. ErrExceeded is returned if hard limit doesn't allow it.
+// Units are allowed if store is unavailable.
+func (t *Tracker) Charge(units int64, now time.Time) error {
+	used, ok, err := t.store.Add(t.day(now), units, t.hard)
+	if err != nil {
+		slog.Warn("Quota charging failed", err, curDir)
+		return nil
+	}
+	if !ok {
+		return ErrExceeded
+	}
+
+	if t.soft > 0 && used >= t.soft && used-units < t.soft {
+		slog.Warn("Quota soft limit reached", used, curDir)
+	}
+	return nil
+}
+
+// Exhaust marks quota of day as spent. It is used when API reports exceeded quota.
+func (t *Tracker) Exhaust(now time.Time) {
+	if t.hard <= 0 {
+		return
+	}
+	if err := t.store.Raise(t.day(now), t.hard); err != nil {
+		slog.Warn("Quota exhausting failed", err, curDir)
+	}
+}
+
+// Used returns units spent in day of now
+func (t *Tracker) Used(now time.Time) int64 {
+	used, err := t.store.Get(t.day(now))
+	if err != nil {
+		slog.Warn("Quota reading failed", err, curDir)
+	}
+	return used
+}
+
+// Level returns state of quota usage in day of now
+func (t *Tracker) Level(now time.Time) Level {
+	used := t.Used(now)
+	switch {
+	case t.hard > 0 && used >= t.hard:
+		return LevelHard
+	case t.soft > 0 && used >= t.soft:
+		return LevelSoft
+	default:
+		return LevelNormal
+	}
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore keeps usage of the current day in process memory
+type MemoryStore struct {
+	mu    sync.Mutex
+	usage map[string]int64 // by day and scope
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{usage: make(map[string]int64)}
+}
+
+// forget removes usage of days before day. Keys of store begin with date.
+func (s *MemoryStore) forget(day string) {
+	date := day[:len("2006-01-02")]
+	for key := range s.usage {
+		if key[:len(date)] < date {
+			delete(s.usage, key)
+		}
+	}
+}
+
+func (s *MemoryStore) Add(day string, units, limit int64) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.forget(day)
+	used := s.usage[day]
+	if limit > 0 && used+units > limit {
+		return used, false, nil
+	}
+	s.usage[day] = used + units
+	return used + units, true, nil
+}
+
+func (s *MemoryStore) Get(day string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usage[day], nil
+}
+
+func (s *MemoryStore) Raise(day string, used int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.forget(day)
+	if s.usage[day] < used {
+		s.usage[day] = used
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package quota
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	baseKey = "quota:"
+
+	// usageTTL keeps usage of day a while after Pacific day is over
+	usageTTL = 48 * time.Hour
+)
+
+// addScript increments usage unless it exceeds limit.
+// KEYS[1] - usage key; ARGV - units, limit, ttl seconds.
+var addScript = redis.NewScript(`
+local used = tonumber(redis.call("GET", KEYS[1]) or "0")
+local units, limit = tonumber(ARGV[1]), tonumber(ARGV[2])
+if limit > 0 and used + units > limit then
+	return {used, 0}
+end
+used = redis.call("INCRBY", KEYS[1], units)
+redis.call("EXPIRE", KEYS[1], ARGV[3])
+return {used, 1}
+`)
+
+// raiseScript sets usage if it is greater.
+// KEYS[1] - usage key; ARGV - used, ttl seconds.
+var raiseScript = redis.NewScript(`
+local used = tonumber(redis.call("GET", KEYS[1]) or "0")
+if used < tonumber(ARGV[1]) then
+	redis.call("SET", KEYS[1], ARGV[1], "EX", ARGV[2])
+end
+return 0
+`)
+
+var _ Store = (*RedisStore)(nil)
+
+// RedisStore keeps usage in Redis, so it is shared by replicas
+type RedisStore struct {
+	Redis *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{Redis: client}
+}
+
+func usageKey(day string) string {
+	return baseKey + day
+}
+
+func (s *RedisStore) Add(day string, units, limit int64) (int64, bool, error) {
+	result, err := addScript.Run(s.Redis, []string{usageKey(day)},
+		units, limit, int64(usageTTL.Seconds())).Result()
+	if err != nil {
+		return 0, false, err
+	}
+
+	values, _ := result.([]interface{})
+	if len(values) != 2 {
+		return 0, false, redis.Nil
+	}
+	used, _ := values[0].(int64)
+	added, _ := values[1].(int64)
+	return used, added == 1, nil
+}
+
+func (s *RedisStore) Get(day string) (int64, error) {
+	used, err := s.Redis.Get(usageKey(day)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return used, err
+}
+
+func (s *RedisStore) Raise(day string, used int64) error {
+	return raiseScript.Run(s.Redis, []string{usageKey(day)}, used, int64(usageTTL.Seconds())).Err()
+}
@@ -0,0 +1,78 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MediaPath is a path prefix of signed media links
+	MediaPath = "/media/"
+
+	expiresParam   = "expires"
+	signatureParam = "signature"
+)
+
+var (
+	ErrExpired   = errors.New("link expired")
+	ErrSignature = errors.New("invalid link signature")
+)
+
+// URLSigner makes short-lived links of media files signed by HMAC-SHA256
+type URLSigner struct {
+	key     []byte
+	baseURL string
+	ttl     time.Duration
+}
+
+// NewURLSigner creates signer of links to baseURL valid for ttl
+func NewURLSigner(key []byte, baseURL string, ttl time.Duration) *URLSigner {
+	return &URLSigner{
+		key:     key,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		ttl:     ttl,
+	}
+}
+
+func (s *URLSigner) signature(name string, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(name + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign returns link of media file and its expiration time
+func (s *URLSigner) Sign(name string, now time.Time) (string, time.Time) {
+	expires := now.Add(s.ttl).Truncate(time.Second)
+
+	query := url.Values{
+		expiresParam:   {strconv.FormatInt(expires.Unix(), 10)},
+		signatureParam: {s.signature(name, expires.Unix())},
+	}
+	return s.baseURL + MediaPath + url.PathEscape(name) + "?" + query.Encode(), expires
+}
+
+// Verify checks signature and expiration of link query for media file name.
+// It returns expiration time of valid link.
+func (s *URLSigner) Verify(name string, query url.Values, now time.Time) (time.Time, error) {
+	expires, err := strconv.ParseInt(query.Get(expiresParam), 10, 64)
+	if err != nil {
+		return time.Time{}, ErrSignature
+	}
+
+	want := s.signature(name, expires)
+	if !hmac.Equal([]byte(want), []byte(query.Get(signatureParam))) {
+		return time.Time{}, ErrSignature
+	}
+
+	expiresAt := time.Unix(expires, 0)
+	if !now.Before(expiresAt) {
+		return time.Time{}, ErrExpired
+	}
+	return expiresAt, nil
+}
@@ -0,0 +1,215 @@
+package upstream
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+var (
+	curDir = "/libs/upstream"
+
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
+
+// State is a state of circuit breaker
+type State int
+
+const (
+	StateClosed   State = iota // requests pass
+	StateOpen                  // requests fail fast
+	StateHalfOpen              // one probe request passes
+)
+
+var stateNames = map[State]string{
+	StateClosed:   "closed",
+	StateOpen:     "open",
+	StateHalfOpen: "half_open",
+}
+
+func (s State) String() string {
+	return stateNames[s]
+}
+
+// BreakerConfig tells when breaker opens and how long it stays open
+type BreakerConfig struct {
+	// Threshold is a number of consecutive failures opening breaker. Zero never opens it.
+	Threshold int
+	// Cooldown is a time before probe request of open breaker
+	Cooldown time.Duration
+}
+
+// Breaker is a circuit breaker of upstream host
+type Breaker struct {
+	host string
+	cfg  BreakerConfig
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	onChange func(host string, state State)
+}
+
+// setState must be called with mu locked
+func (b *Breaker) setState(state State, now time.Time) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	if state == StateOpen {
+		b.openedAt = now
+	}
+
+	slog.Info("Circuit breaker state changed", b.host, state.String(), curDir)
+	if b.onChange != nil {
+		b.onChange(b.host, state)
+	}
+}
+
+// Allow reports whether request may be sent. ErrCircuitOpen is returned while upstream is down.
+// Open breaker lets one probe request pass after cooldown.
+func (b *Breaker) Allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.openedAt) < b.cfg.Cooldown {
+			return ErrCircuitOpen
+		}
+		b.setState(StateHalfOpen, now)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success records that upstream responded
+func (b *Breaker) Success(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures, b.probing = 0, false
+	b.setState(StateClosed, now)
+}
+
+// Failure records that upstream is unreachable or failed
+func (b *Breaker) Failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || b.cfg.Threshold > 0 && b.failures >= b.cfg.Threshold {
+		b.setState(StateOpen, now)
+	}
+}
+
+// Cancel records that request gave no answer about upstream, like canceled one
+func (b *Breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// Breakers keeps circuit breaker of every upstream host
+type Breakers struct {
+	cfg BreakerConfig
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+	onChange func(host string, state State)
+}
+
+// NewBreakers creates breakers of hosts; breakers of other hosts are created on demand
+func NewBreakers(cfg BreakerConfig, hosts ...string) *Breakers {
+	b := &Breakers{
+		cfg:      cfg,
+		breakers: make(map[string]*Breaker),
+	}
+	for _, host := range hosts {
+		b.For(host)
+	}
+	return b
+}
+
+// Configure changes config of every breaker, present and created later
+func (b *Breakers) Configure(cfg BreakerConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.cfg = cfg
+	for _, breaker := range b.breakers {
+		breaker.mu.Lock()
+		breaker.cfg = cfg
+		breaker.mu.Unlock()
+	}
+}
+
+// OnChange sets callback called on every state change of breaker
+func (b *Breakers) OnChange(f func(host string, state State)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.onChange = f
+	for _, breaker := range b.breakers {
+		breaker.mu.Lock()
+		breaker.onChange = f
+		breaker.mu.Unlock()
+	}
+}
+
+// For returns breaker of host
+func (b *Breakers) For(host string) *Breaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	breaker, ok := b.breakers[host]
+	if !ok {
+		breaker = &Breaker{host: host, cfg: b.cfg, onChange: b.onChange}
+		b.breakers[host] = breaker
+	}
+	return breaker
+}
+
+// HostState is a state of breaker of host
+type HostState struct {
+	Host  string
+	State State
+}
+
+// States returns state of every breaker ordered by host
+func (b *Breakers) States() []HostState {
+	b.mu.Lock()
+	hosts := make([]string, 0, len(b.breakers))
+	for host := range b.breakers {
+		hosts = append(hosts, host)
+	}
+	b.mu.Unlock()
+
+	sort.Strings(hosts)
+	states := make([]HostState, 0, len(hosts))
+	for _, host := range hosts {
+		states = append(states, HostState{Host: host, State: b.For(host).State()})
+	}
+	return states
+}
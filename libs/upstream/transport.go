@@ -0,0 +1,166 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy tells how failed requests are repeated
+type RetryPolicy struct {
+	// Attempts is a maximum number of attempts including the first one
+	Attempts int
+	// BaseDelay is a delay before the first retry, it doubles on every next one
+	BaseDelay time.Duration
+	// MaxDelay limits delay; Retry-After longer than it isn't waited
+	MaxDelay time.Duration
+}
+
+// backoff returns jittered delay before retry after attempt, counted from zero
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << attempt
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	// Full jitter spreads retries of concurrent requests
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// retryAfter parses Retry-After header in seconds or HTTP date
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now), true
+	}
+	return 0, false
+}
+
+// retryable reports that status means temporary failure
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+type retryHookKey struct{}
+
+// WithRetryHook returns context whose requests call hook before every retry made by Transport.
+// Request isn't retried if hook returns error; the last response or error is returned instead.
+func WithRetryHook(ctx context.Context, hook func() error) context.Context {
+	return context.WithValue(ctx, retryHookKey{}, hook)
+}
+
+// allowRetry calls retry hook of context if any
+func allowRetry(ctx context.Context) bool {
+	hook, ok := ctx.Value(retryHookKey{}).(func() error)
+	return !ok || hook() == nil
+}
+
+// Transport is an http.RoundTripper retrying temporary failures
+// with jittered exponential backoff behind per-host circuit breakers.
+type Transport struct {
+	// Base makes requests. http.DefaultTransport if nil.
+	Base     http.RoundTripper
+	Breakers *Breakers
+	Policy   RetryPolicy
+}
+
+func NewTransport(breakers *Breakers, policy RetryPolicy) *Transport {
+	return &Transport{
+		Breakers: breakers,
+		Policy:   policy,
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// wait sleeps delay unless context is done
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		breaker = t.Breakers.For(req.URL.Host)
+		ctx     = req.Context()
+		// Request with body can be repeated only if body can be read again
+		repeatable = req.Body == nil || req.GetBody != nil
+	)
+
+	for attempt := 0; ; attempt++ {
+		if err := breaker.Allow(time.Now()); err != nil {
+			return nil, fmt.Errorf("%s: %w", req.URL.Host, err)
+		}
+
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := t.base().RoundTrip(req)
+		now := time.Now()
+
+		// Request canceled or timed out by caller doesn't tell anything about upstream
+		if ctx.Err() != nil {
+			breaker.Cancel()
+			return resp, err
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+			breaker.Failure(now)
+			delay = t.Policy.backoff(attempt)
+		default:
+			breaker.Success(now)
+			if !retryable(resp.StatusCode) {
+				return resp, nil
+			}
+			delay = t.Policy.backoff(attempt)
+		}
+
+		if resp != nil {
+			if after, ok := retryAfter(resp, now); ok {
+				delay = after
+			}
+		}
+
+		// The last response or error is returned as is
+		if attempt+1 >= t.Policy.Attempts || !repeatable || delay > t.Policy.MaxDelay || !allowRetry(ctx) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if err := wait(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
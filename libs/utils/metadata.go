@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/serial"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+)
+
+// partNames maps meta data parts to YouTube API part names
+var partNames = map[proto.MetadataPart]string{
+	proto.MetadataPart_SNIPPET:         "snippet",
+	proto.MetadataPart_CONTENT_DETAILS: "contentDetails",
+	proto.MetadataPart_STATISTICS:      "statistics",
+}
+
+// Parts is a set of meta data parts. Snippet is always included.
+type Parts uint8
+
+// NewParts returns set of parts, unknown parts skipped
+func NewParts(parts ...proto.MetadataPart) Parts {
+	set := Parts(1) << proto.MetadataPart_SNIPPET
+	for _, part := range parts {
+		if _, ok := partNames[part]; ok {
+			set |= 1 << part
+		}
+	}
+	return set
+}
+
+// PartsOf returns parts kept in meta data. Missing meta data has no parts.
+func PartsOf(meta *proto.VideoMetadata) Parts {
+	if meta == nil {
+		return 0
+	}
+	return NewParts(meta.GetParts()...)
+}
+
+func (p Parts) Has(part proto.MetadataPart) bool {
+	return p&(1<<part) != 0
+}
+
+// Contains reports that every part of other is in set
+func (p Parts) Contains(other Parts) bool {
+	return p&other == other
+}
+
+// List returns parts of set in order of proto enum
+func (p Parts) List() []proto.MetadataPart {
+	parts := make([]proto.MetadataPart, 0, len(partNames))
+	for part := proto.MetadataPart_SNIPPET; part <= proto.MetadataPart_STATISTICS; part++ {
+		if p.Has(part) {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// Names returns YouTube API names of parts
+func (p Parts) Names() []string {
+	names := make([]string, 0, len(partNames))
+	for _, part := range p.List() {
+		names = append(names, partNames[part])
+	}
+	return names
+}
+
+// ParseISODuration parses ISO 8601 duration of YouTube video, like P1DT2H3M4S.
+// Years and months aren't supported.
+func ParseISODuration(src string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(src, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("invalid duration %q", src)
+	}
+
+	var (
+		duration time.Duration
+		inTime   bool
+		number   string
+	)
+	for _, char := range rest {
+		if char >= '0' && char <= '9' {
+			number += string(char)
+			continue
+		}
+		if char == 'T' && !inTime && number == "" {
+			inTime = true
+			continue
+		}
+
+		var unit time.Duration
+		switch {
+		case !inTime && char == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && char == 'D':
+			unit = 24 * time.Hour
+		case inTime && char == 'H':
+			unit = time.Hour
+		case inTime && char == 'M':
+			unit = time.Minute
+		case inTime && char == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", src)
+		}
+
+		value, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", src)
+		}
+		duration += time.Duration(value) * unit
+		number = ""
+	}
+
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %q", src)
+	}
+	return duration, nil
+}
+
+// parseCount returns nil for hidden count
+func parseCount(src string) *int64 {
+	count, err := strconv.ParseInt(src, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &count
+}
+
+// NewVideoMetadata builds meta data of parts present in item
+func NewVideoMetadata(item *serial.Item) *proto.VideoMetadata {
+	var (
+		snippet = item.Snippet
+		parts   = []proto.MetadataPart{proto.MetadataPart_SNIPPET}
+	)
+
+	meta := &proto.VideoMetadata{
+		ChannelId:            snippet.ChannelId,
+		Description:          snippet.Description,
+		Tags:                 snippet.Tags,
+		CategoryId:           snippet.CategoryId,
+		LiveBroadcastContent: snippet.LiveBroadcastContent,
+		DefaultLanguage:      snippet.DefaultLanguage,
+	}
+	if publishedAt, err := time.Parse(time.RFC3339, snippet.PublishedAt); err == nil {
+		meta.PublishedAt = publishedAt.Unix()
+	}
+
+	if details := item.ContentDetails; details != nil {
+		duration, _ := ParseISODuration(details.Duration)
+		meta.Duration = int64(duration.Seconds())
+		parts = append(parts, proto.MetadataPart_CONTENT_DETAILS)
+	}
+	if stats := item.Statistics; stats != nil {
+		meta.ViewCount = parseCount(stats.ViewCount)
+		meta.LikeCount = parseCount(stats.LikeCount)
+		parts = append(parts, proto.MetadataPart_STATISTICS)
+	}
+
+	meta.Parts = parts
+	return meta
+}
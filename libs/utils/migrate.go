@@ -1,29 +1,56 @@
 package utils
 
 import (
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/fluxx1on/thumbnails_microservice/external/serial"
 	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
 	"github.com/go-redis/redis"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 )
 
 var (
 	curDir = "/libs/utils"
 )
 
-func NewErrorThumbnailResponse(url string, err string) *proto.ThumbnailResponse {
+const (
+	errorDomain = "thumbnails"
+)
+
+func NewErrorThumbnailResponse(url string, code proto.ErrorCode, err string) *proto.ThumbnailResponse {
 	return &proto.ThumbnailResponse{
 		Content: &proto.ThumbnailResponse_Error{
 			Error: &proto.ErrorResponse{
 				Url:          url,
 				ErrorMessage: err,
+				Code:         code,
 			},
 		},
 	}
 }
 
-func cachedThumbnailResponse(args *redis.StringStringMapCmd) *proto.ThumbnailResponse {
+// NewStatusError is an RPC-level error carrying ErrorCode in errdetails.ErrorInfo
+func NewStatusError(grpcCode codes.Code, code proto.ErrorCode, msg string) error {
+	st := status.New(grpcCode, msg)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: code.String(),
+		Domain: errorDomain,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// cachedThumbnailResponse builds response of video hash.
+// Image is read from blob store only if withFile is set.
+func cachedThumbnailResponse(args *redis.StringStringMapCmd, withFile bool) *proto.ThumbnailResponse {
 	values := args.Val()
 
 	width, err1 := strconv.Atoi(values["width"])
@@ -32,11 +59,27 @@ func cachedThumbnailResponse(args *redis.StringStringMapCmd) *proto.ThumbnailRes
 		return nil
 	}
 
-	data := ReadMediaFile(values["id"])
-	if data == nil {
+	resolution, ok := ParseResolution(values["resolution"])
+	if !ok {
 		return nil
 	}
 
+	// Entries cached before fetched_at was stored are the oldest ones
+	fetchedAt, _ := strconv.ParseInt(values["fetched_at"], 10, 64)
+	modifiedAt, err := strconv.ParseInt(values["modified_at"], 10, 64)
+	if err != nil {
+		modifiedAt = fetchedAt
+	}
+
+	// Entries cached before meta data was stored have no parts
+	var metadata *proto.VideoMetadata
+	if src, ok := values["metadata"]; ok {
+		metadata = &proto.VideoMetadata{}
+		if err := protobuf.Unmarshal([]byte(src), metadata); err != nil {
+			metadata = nil
+		}
+	}
+
 	thumbnailResponse := &proto.ThumbnailResponse{
 		Content: &proto.ThumbnailResponse_Thumbnail{
 			Thumbnail: &proto.Thumbnail{
@@ -46,16 +89,43 @@ func cachedThumbnailResponse(args *redis.StringStringMapCmd) *proto.ThumbnailRes
 				Title:        values["title"],
 				Width:        int32(width),
 				Height:       int32(height),
-				File:         data,
+				Resolution:   resolution,
+				Available:    SplitResolutions(values["available"]),
+				FetchedAt:    fetchedAt,
+				Etag:         values["blob"],
+				LastModified: modifiedAt,
+				Metadata:     metadata,
 			},
 		},
 	}
 
+	if withFile {
+		// Missing or corrupted blob is a cache miss, so thumbnail is fetched again
+		data := blobstore.Media.Get(values["blob"])
+		if data == nil {
+			return nil
+		}
+		thumb := thumbnailResponse.GetThumbnail()
+		thumb.File = data
+		thumb.MimeType = http.DetectContentType(data)
+	}
+
 	return thumbnailResponse
 }
 
+// NewCachedMetadataResponse builds response of video hash without reading image
+func NewCachedMetadataResponse(args *redis.StringStringMapCmd) *proto.ThumbnailResponse {
+	return cachedThumbnailResponse(args, false)
+}
+
+// requestedThumbnailResponse builds response of API video.
+// Video without downloaded image has no image version.
 func requestedThumbnailResponse(video *serial.Video) *proto.ThumbnailResponse {
-	item := video.I
+	var (
+		item          = video.I
+		resolution, _ = ParseResolution(item.GetResolution())
+		now           = time.Now().Unix()
+	)
 
 	thumbnailResponse := &proto.ThumbnailResponse{
 		Content: &proto.ThumbnailResponse_Thumbnail{
@@ -66,18 +136,29 @@ func requestedThumbnailResponse(video *serial.Video) *proto.ThumbnailResponse {
 				Title:        item.GetTitle(),
 				Width:        item.GetWidth(),
 				Height:       item.GetHeight(),
-				File:         video.GetData(),
+				Resolution:   resolution,
+				Available:    ParseResolutions(item.Available()...),
+				FetchedAt:    now,
+				Metadata:     NewVideoMetadata(item),
 			},
 		},
 	}
 
+	if data := video.GetData(); data != nil {
+		thumb := thumbnailResponse.GetThumbnail()
+		thumb.File = data
+		thumb.MimeType = http.DetectContentType(data)
+		thumb.Etag = blobstore.Digest(data)
+		thumb.LastModified = now
+	}
+
 	return thumbnailResponse
 }
 
 func NewThumbnailResponse(cmd interface{}) *proto.ThumbnailResponse {
 	switch args := cmd.(type) {
 	case *redis.StringStringMapCmd:
-		return cachedThumbnailResponse(args)
+		return cachedThumbnailResponse(args, true)
 	case *serial.Video:
 		return requestedThumbnailResponse(args)
 	default:
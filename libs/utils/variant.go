@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/serial"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/imaging"
+)
+
+// resolutionNames maps proto resolutions to YouTube API names
+var resolutionNames = map[proto.Resolution]string{
+	proto.Resolution_DEFAULT:  serial.ResolutionDefault,
+	proto.Resolution_MEDIUM:   serial.ResolutionMedium,
+	proto.Resolution_HIGH:     serial.ResolutionHigh,
+	proto.Resolution_STANDARD: serial.ResolutionStandard,
+	proto.Resolution_MAXRES:   serial.ResolutionMaxres,
+}
+
+// ResolutionName returns YouTube API name of resolution
+func ResolutionName(res proto.Resolution) string {
+	if name, ok := resolutionNames[res]; ok {
+		return name
+	}
+	return serial.ResolutionMaxres
+}
+
+// ParseResolution returns resolution by YouTube API name
+func ParseResolution(name string) (proto.Resolution, bool) {
+	for res, resName := range resolutionNames {
+		if resName == name {
+			return res, true
+		}
+	}
+	return proto.Resolution_MAXRES, false
+}
+
+// ParseResolutions returns resolutions by YouTube API names, unknown names skipped
+func ParseResolutions(names ...string) []proto.Resolution {
+	resolutions := make([]proto.Resolution, 0, len(names))
+	for _, name := range names {
+		if res, ok := ParseResolution(name); ok {
+			resolutions = append(resolutions, res)
+		}
+	}
+	return resolutions
+}
+
+// JoinResolutions is an inverse of SplitResolutions
+func JoinResolutions(resolutions ...proto.Resolution) string {
+	names := make([]string, 0, len(resolutions))
+	for _, res := range resolutions {
+		names = append(names, ResolutionName(res))
+	}
+	return strings.Join(names, ",")
+}
+
+// SplitResolutions parses comma separated resolution names
+func SplitResolutions(src string) []proto.Resolution {
+	if src == "" {
+		return nil
+	}
+	return ParseResolutions(strings.Split(src, ",")...)
+}
+
+// ResolutionCandidates returns resolutions in order they should be tried
+// to satisfy preferred resolution with fallback policy.
+func ResolutionCandidates(preferred proto.Resolution, fallback proto.FallbackPolicy) []proto.Resolution {
+	var (
+		name       = ResolutionName(preferred)
+		position   int
+		candidates = make([]proto.Resolution, 0, len(serial.Resolutions))
+	)
+
+	for i, resName := range serial.Resolutions {
+		if resName == name {
+			position = i
+		}
+	}
+
+	candidates = append(candidates, preferred)
+	switch fallback {
+	case proto.FallbackPolicy_FALLBACK_LOWER:
+		for i := position - 1; i >= 0; i-- {
+			candidates = append(candidates, ParseResolutions(serial.Resolutions[i])...)
+		}
+	case proto.FallbackPolicy_FALLBACK_HIGHER:
+		for i := position + 1; i < len(serial.Resolutions); i++ {
+			candidates = append(candidates, ParseResolutions(serial.Resolutions[i])...)
+		}
+	}
+
+	return candidates
+}
+
+// PickResolution selects the best available resolution for preferred one.
+// False means that no available resolution satisfies fallback policy.
+func PickResolution(available []proto.Resolution, preferred proto.Resolution,
+	fallback proto.FallbackPolicy) (proto.Resolution, bool) {
+	for _, candidate := range ResolutionCandidates(preferred, fallback) {
+		for _, res := range available {
+			if res == candidate {
+				return res, true
+			}
+		}
+	}
+	return preferred, false
+}
+
+// Variant is a requested thumbnail of video
+type Variant struct {
+	ID         string
+	Resolution proto.Resolution
+	Fallback   proto.FallbackPolicy
+
+	// Spec is a derivative made from original thumbnail
+	Spec imaging.Spec
+	// Delivery tells whether image is inlined or linked
+	Delivery proto.Delivery
+	// IfNoneMatch lists etags of image client already has
+	IfNoneMatch string
+	// MetadataOnly skips reading and downloading of image
+	MetadataOnly bool
+	// Parts are meta data parts requested from YouTube
+	Parts Parts
+}
+
+// NewVariant builds Variant from request and parsed video ID
+func NewVariant(videoID string, req *proto.GetThumbnailRequest) Variant {
+	return Variant{
+		ID:          videoID,
+		Resolution:  req.GetResolution(),
+		Fallback:    req.GetFallback(),
+		Spec:        imaging.NewSpec(req),
+		Delivery:    req.GetDelivery(),
+		IfNoneMatch: req.GetIfNoneMatch(),
+
+		MetadataOnly: req.IncludeFile != nil && !req.GetIncludeFile(),
+		Parts:        NewParts(req.GetParts()...),
+	}
+}
+
+// VariantKey is a unique name of video thumbnail in resolution
+func VariantKey(videoID string, res proto.Resolution) string {
+	return videoID + ":" + ResolutionName(res)
+}
+
+// Key returns VariantKey of preferred resolution
+func (v Variant) Key() string {
+	return VariantKey(v.ID, v.Resolution)
+}
+
+// ThumbnailKey returns VariantKey of thumbnail
+func ThumbnailKey(thumb *proto.Thumbnail) string {
+	return VariantKey(thumb.GetId(), thumb.GetResolution())
+}
+
+// Answers reports that thumbnail satisfies variant.
+// Thumbnail without image answers metadata only variants and variants whose client has the image.
+func (v Variant) Answers(thumb *proto.Thumbnail) bool {
+	if thumb.GetId() != v.ID {
+		return false
+	}
+	withoutImage := len(thumb.GetFile()) == 0 && !v.Matches(v.Etag(thumb.GetEtag()))
+	if !v.MetadataOnly && withoutImage || !v.Covers(thumb) {
+		return false
+	}
+	res, ok := PickResolution(thumb.GetAvailable(), v.Resolution, v.Fallback)
+	return ok && res == thumb.GetResolution()
+}
+
+// Covers reports that thumbnail has every meta data part of variant
+func (v Variant) Covers(thumb *proto.Thumbnail) bool {
+	return PartsOf(thumb.GetMetadata()).Contains(v.Parts)
+}
+
+// NotModified reports that client already has image of thumbnail
+func (v Variant) NotModified(thumb *proto.Thumbnail) bool {
+	return v.Matches(thumb.GetEtag())
+}
+
+// Etag returns etag of image answering variant, which is made from original image with digest
+func (v Variant) Etag(digest string) string {
+	if digest == "" || v.Spec.IsOriginal() {
+		return digest
+	}
+	return digest + v.Spec.Suffix()
+}
+
+// Matches reports that IfNoneMatch lists etag.
+// IfNoneMatch is a comma separated list of etags like If-None-Match header.
+// Etags are compared by weak comparison of RFC 7232, so weak ones match strong ones.
+func (v Variant) Matches(etag string) bool {
+	if v.IfNoneMatch == "" || etag == "" {
+		return false
+	}
+
+	etag = opaqueTag(etag)
+	for _, tag := range strings.Split(v.IfNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || opaqueTag(tag) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// opaqueTag strips weakness indicator and quotes of entity tag
+func opaqueTag(tag string) string {
+	return strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+}
@@ -0,0 +1,48 @@
+package youtube_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
+)
+
+func TestChunkIDs(t *testing.T) {
+	ids := make([]string, 120)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("video%d", i)
+	}
+
+	tests := []struct {
+		name  string
+		ids   []string
+		sizes []int
+	}{
+		{name: "Test #1", ids: ids, sizes: []int{50, 50, 20}},
+		{name: "Test #2", ids: ids[:50], sizes: []int{50}},
+		{name: "Test #3", ids: ids[:51], sizes: []int{50, 1}},
+		{name: "Test #4", ids: ids[:3], sizes: []int{3}},
+		{name: "Test #5", ids: nil, sizes: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := youtube.ChunkIDs(tt.ids, youtube.MaxVideosPerRequest)
+			if len(chunks) != len(tt.sizes) {
+				t.Fatalf("ChunkIDs() = %d chunks, want %d", len(chunks), len(tt.sizes))
+			}
+
+			next := 0
+			for i, chunk := range chunks {
+				if len(chunk) != tt.sizes[i] {
+					t.Errorf("chunk #%d size = %d, want %d", i, len(chunk), tt.sizes[i])
+				}
+				for _, id := range chunk {
+					if id != tt.ids[next] {
+						t.Errorf("chunk #%d keeps %s, want %s", i, id, tt.ids[next])
+					}
+					next++
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package youtube_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
+	"github.com/fluxx1on/thumbnails_microservice/external/serial"
+	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+)
+
+// imageServer answers image of video by path /<id>, /slow and /missing paths fail
+func imageServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := active.Add(1)
+		defer active.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+
+		switch id := strings.TrimPrefix(r.URL.Path, "/"); id {
+		case "slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			time.Sleep(5 * time.Millisecond)
+			w.Write([]byte("image of " + id))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &peak
+}
+
+func TestGetThumbnails(t *testing.T) {
+	server, peak := imageServer(t)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	many := make([]string, 40)
+	for i := range many {
+		many[i] = fmt.Sprintf("video%d", i)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		workers int
+		ids     []string
+		want    map[string]proto.ErrorCode // failure reason by ID, others are downloaded
+	}{
+		{
+			name:    "Test #1",
+			ctx:     context.Background(),
+			workers: 3,
+			ids:     many,
+		},
+		{
+			name:    "Test #2",
+			ctx:     context.Background(),
+			workers: 2,
+			ids:     []string{"first", "missing", "slow", "last"},
+			want: map[string]proto.ErrorCode{
+				"missing": proto.ErrorCode_NOT_FOUND,
+				"slow":    proto.ErrorCode_UPSTREAM_TIMEOUT,
+			},
+		},
+		{
+			name:    "Test #3",
+			ctx:     canceled,
+			workers: 2,
+			ids:     []string{"first", "last"},
+			want: map[string]proto.ErrorCode{
+				"first": proto.ErrorCode_UPSTREAM_ERROR,
+				"last":  proto.ErrorCode_UPSTREAM_ERROR,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peak.Store(0)
+			client := youtube.NewAPIClient(&config.YouTubeAPI{
+				ImageTimeout: 200 * time.Millisecond,
+				ImageWorkers: tt.workers,
+			})
+
+			images := make(map[youtube.ImageKey]string, len(tt.ids))
+			for _, id := range tt.ids {
+				images[youtube.ImageKey{ID: id, Resolution: proto.Resolution_HIGH}] = server.URL + "/" + id
+			}
+
+			thumbnails, errByKey := client.GetThumbnails(tt.ctx, images)
+
+			if got := int(peak.Load()); got > tt.workers {
+				t.Errorf("GetThumbnails() made %d concurrent requests, want at most %d", got, tt.workers)
+			}
+			for _, id := range tt.ids {
+				key := youtube.ImageKey{ID: id, Resolution: proto.Resolution_HIGH}
+				if want, ok := tt.want[id]; ok {
+					if got := youtube.ErrorCode(errByKey[key]); errByKey[key] == nil || got != want {
+						t.Errorf("GetThumbnails() %s error = %v, want %s", id, errByKey[key], want)
+					}
+					continue
+				}
+				if errByKey[key] != nil || string(thumbnails[key]) != "image of "+id {
+					t.Errorf("GetThumbnails() %s = %q, %v, want its image", id, thumbnails[key], errByKey[key])
+				}
+			}
+		})
+	}
+}
+
+func TestStreamThumbnails(t *testing.T) {
+	server, _ := imageServer(t)
+	client := youtube.NewAPIClient(&config.YouTubeAPI{ImageTimeout: 500 * time.Millisecond, ImageWorkers: 2})
+
+	var (
+		fast   = youtube.ImageKey{ID: "fast", Resolution: proto.Resolution_HIGH}
+		slow   = youtube.ImageKey{ID: "slow", Resolution: proto.Resolution_HIGH}
+		images = map[youtube.ImageKey]string{fast: server.URL + "/fast", slow: server.URL + "/slow"}
+		start  = time.Now()
+		loaded []youtube.ImageKey
+	)
+
+	client.StreamThumbnails(context.Background(), images, func(key youtube.ImageKey, data serial.ThumbnailData, err error) {
+		if key == fast && time.Since(start) > 250*time.Millisecond {
+			t.Errorf("StreamThumbnails() passed fast image after %s", time.Since(start))
+		}
+		loaded = append(loaded, key)
+	})
+
+	if len(loaded) != 2 || loaded[0] != fast || loaded[1] != slow {
+		t.Errorf("StreamThumbnails() passed %v, want fast image before slow one", loaded)
+	}
+}
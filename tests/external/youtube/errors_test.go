@@ -0,0 +1,53 @@
+package youtube_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+)
+
+func TestGetImageErrorCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/quota":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":403,"errors":[{"reason":"quotaExceeded"}]}}`))
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte("image"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want proto.ErrorCode
+	}{
+		{name: "Not found", path: "/missing", want: proto.ErrorCode_NOT_FOUND},
+		{name: "Quota", path: "/quota", want: proto.ErrorCode_QUOTA_EXCEEDED},
+		{name: "Upstream", path: "/broken", want: proto.ErrorCode_UPSTREAM_ERROR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := youtube.GetImage(context.Background(), server.URL+tt.path)
+			if err == nil {
+				t.Fatalf("GetImage() error = nil, want %v", tt.want)
+			}
+			if got := youtube.ErrorCode(err); got != tt.want {
+				t.Errorf("ErrorCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := youtube.GetImage(context.Background(), server.URL+"/image"); err != nil {
+		t.Errorf("GetImage() error = %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package youtube_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
@@ -21,7 +22,7 @@ func TestGetImage(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := youtube.GetImage(tt.args.url)
+			got, err := youtube.GetImage(context.Background(), tt.args.url)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetImage() error = %v, wantErr %v", err, tt.wantErr)
 				return
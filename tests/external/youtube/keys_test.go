@@ -0,0 +1,136 @@
+package youtube_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
+	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/quota"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+// keyTransport answers videos.list by API key of request
+type keyTransport struct{}
+
+func (keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		status = http.StatusOK
+		body   = `{"items":[{"id":"QFxZlKb7W2k","status":{"privacyStatus":"public"}}]}`
+	)
+	switch req.URL.Query().Get("key") {
+	case "invalid":
+		status, body = http.StatusBadRequest, `{"error":{"code":400,"errors":[{"reason":"keyInvalid"}]}}`
+	case "spent":
+		status, body = http.StatusForbidden, `{"error":{"code":403,"errors":[{"reason":"quotaExceeded"}]}}`
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+func useTransport(t *testing.T, transport http.RoundTripper) {
+	saved := http.DefaultTransport
+	http.DefaultTransport = transport
+	t.Cleanup(func() { http.DefaultTransport = saved })
+}
+
+func TestKeyPoolFailover(t *testing.T) {
+	useTransport(t, keyTransport{})
+
+	client := youtube.NewAPIClient(&config.YouTubeAPI{
+		APIKeys:      []string{"invalid", "spent", "good"},
+		KeySelection: youtube.SelectRoundRobin,
+	})
+	client.SetQuota(quota.NewTracker(quota.NewMemoryStore(), 0, 100))
+
+	for i := 0; i < 3; i++ {
+		videos, failed := client.GetVideos(context.Background(), utils.NewParts(), "QFxZlKb7W2k")
+		if len(failed) != 0 || len(videos.Items) != 1 {
+			t.Fatalf("GetVideos() #%d = %d items, failed %v", i, len(videos.Items), failed)
+		}
+	}
+
+	stats := client.KeyStats()
+	for i, want := range []struct {
+		requests    uint64
+		quarantined bool
+	}{{1, true}, {1, true}, {3, false}} {
+		if stats[i].Requests != want.requests || stats[i].QuarantinedUntil.IsZero() == want.quarantined {
+			t.Errorf("KeyStats()[%d] = %v", i, stats[i])
+		}
+	}
+	if got := client.QuotaLevel(); got != quota.LevelNormal {
+		t.Errorf("QuotaLevel() = %d, want %d", got, quota.LevelNormal)
+	}
+}
+
+func TestKeyPoolExhausted(t *testing.T) {
+	useTransport(t, keyTransport{})
+
+	client := youtube.NewAPIClient(&config.YouTubeAPI{APIKeys: []string{"spent"}})
+	client.SetQuota(quota.NewTracker(quota.NewMemoryStore(), 0, 100))
+
+	_, failed := client.GetVideos(context.Background(), utils.NewParts(), "QFxZlKb7W2k")
+	if got := youtube.ErrorCode(failed["QFxZlKb7W2k"]); got != proto.ErrorCode_QUOTA_EXCEEDED {
+		t.Errorf("GetVideos() error code = %s, want QUOTA_EXCEEDED", got)
+	}
+	if got := client.QuotaLevel(); got != quota.LevelHard {
+		t.Errorf("QuotaLevel() = %d, want %d", got, quota.LevelHard)
+	}
+}
+
+// flakyTransport fails videos.list with 503 before answering as keyTransport
+type flakyTransport struct {
+	failures atomic.Int32
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if f.failures.Add(-1) >= 0 {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       io.NopCloser(strings.NewReader(`{"error":{"code":503}}`)),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	}
+	return keyTransport{}.RoundTrip(req)
+}
+
+func TestKeyPoolChargesRetries(t *testing.T) {
+	transport := &flakyTransport{}
+	transport.failures.Store(2)
+	useTransport(t, transport)
+
+	youtube.SetupTransport(&config.YouTubeAPI{
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+		RetryAttempts:    3,
+		RetryBaseDelay:   time.Millisecond,
+		RetryMaxDelay:    10 * time.Millisecond,
+	})
+	t.Cleanup(func() { youtube.SetupTransport(&config.YouTubeAPI{}) })
+
+	client := youtube.NewAPIClient(&config.YouTubeAPI{APIKeys: []string{"good"}})
+	client.SetQuota(quota.NewTracker(quota.NewMemoryStore(), 0, 100))
+
+	videos, failed := client.GetVideos(context.Background(), utils.NewParts(), "QFxZlKb7W2k")
+	if len(failed) != 0 || len(videos.Items) != 1 {
+		t.Fatalf("GetVideos() = %d items, failed %v", len(videos.Items), failed)
+	}
+
+	stats := client.KeyStats()
+	if want := int64(3 * youtube.VideosListCost); stats[0].Requests != 3 || stats[0].QuotaUsed != want {
+		t.Errorf("KeyStats()[0] = %v, want 3 requests and quota used %d", stats[0], want)
+	}
+}
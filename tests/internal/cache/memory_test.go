@@ -0,0 +1,135 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/cache"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+var available = []proto.Resolution{
+	proto.Resolution_DEFAULT, proto.Resolution_MEDIUM, proto.Resolution_HIGH,
+}
+
+func newThumbnail(id string, res proto.Resolution) *proto.Thumbnail {
+	return &proto.Thumbnail{
+		Id:         id,
+		Resolution: res,
+		Available:  available,
+		File:       []byte(id),
+	}
+}
+
+func TestMemoryCacheResolution(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(10, 0)
+	c.SetSeries(ctx,
+		newThumbnail("Gmlh0NrvzP0", proto.Resolution_HIGH),
+		newThumbnail("Gmlh0NrvzP0", proto.Resolution_DEFAULT),
+	)
+
+	tests := []struct {
+		name    string
+		variant utils.Variant
+		want    proto.Resolution
+		wantHit bool
+	}{
+		{name: "Fallback", variant: utils.Variant{ID: "Gmlh0NrvzP0"}, want: proto.Resolution_HIGH, wantHit: true},
+		{name: "Exact", variant: utils.Variant{ID: "Gmlh0NrvzP0", Resolution: proto.Resolution_DEFAULT}, want: proto.Resolution_DEFAULT, wantHit: true},
+		{name: "Strict", variant: utils.Variant{ID: "Gmlh0NrvzP0", Fallback: proto.FallbackPolicy_FALLBACK_NONE}, wantHit: false},
+		{name: "Not cached", variant: utils.Variant{ID: "Gmlh0NrvzP0", Resolution: proto.Resolution_MEDIUM}, wantHit: false},
+		{name: "Other video", variant: utils.Variant{ID: "QFxZlKb7W2k"}, wantHit: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := c.Get(ctx, tt.variant)
+			if (got != nil) != tt.wantHit {
+				t.Fatalf("Get() = %v, wantHit %v", got, tt.wantHit)
+			}
+			if got != nil && got.GetThumbnail().GetResolution() != tt.want {
+				t.Errorf("Get() resolution = %v, want %v", got.GetThumbnail().GetResolution(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(2, 0)
+
+	c.SetSeries(ctx, newThumbnail("aaaaaaaaaaa", proto.Resolution_HIGH))
+	c.SetSeries(ctx, newThumbnail("bbbbbbbbbbb", proto.Resolution_HIGH))
+
+	// "aaaaaaaaaaa" becomes the most recently used
+	if resp, _ := c.Get(ctx, utils.Variant{ID: "aaaaaaaaaaa"}); resp == nil {
+		t.Fatal("Get() missed cached thumbnail")
+	}
+	c.SetSeries(ctx, newThumbnail("ccccccccccc", proto.Resolution_HIGH))
+
+	hits, misses, _ := c.GetSeries(ctx,
+		utils.Variant{ID: "aaaaaaaaaaa"},
+		utils.Variant{ID: "bbbbbbbbbbb"},
+		utils.Variant{ID: "ccccccccccc"},
+	)
+	if c.Len() != 2 || len(hits) != 2 || len(misses) != 1 || misses[0].ID != "bbbbbbbbbbb" {
+		t.Errorf("GetSeries() hits = %d, misses = %v, len = %d", len(hits), misses, c.Len())
+	}
+}
+
+func TestMemoryCacheMaxAge(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(0, 0)
+	c.SetMaxAge(time.Hour)
+
+	fresh := newThumbnail("aaaaaaaaaaa", proto.Resolution_HIGH)
+	fresh.FetchedAt = time.Now().Unix()
+	expired := newThumbnail("bbbbbbbbbbb", proto.Resolution_HIGH)
+	expired.FetchedAt = time.Now().Add(-2 * time.Hour).Unix()
+	c.SetSeries(ctx, fresh, expired)
+
+	if resp, _ := c.Get(ctx, utils.Variant{ID: "aaaaaaaaaaa"}); resp == nil {
+		t.Error("Get() missed fresh thumbnail")
+	}
+	if resp, _ := c.Get(ctx, utils.Variant{ID: "bbbbbbbbbbb"}); resp != nil {
+		t.Error("Get() found expired thumbnail")
+	}
+}
+
+func TestMemoryCacheLastModified(t *testing.T) {
+	ctx := context.Background()
+	variant := utils.Variant{ID: "Gmlh0NrvzP0", Resolution: proto.Resolution_HIGH}
+
+	versioned := func(etag string, modified int64) *proto.Thumbnail {
+		thumb := newThumbnail("Gmlh0NrvzP0", proto.Resolution_HIGH)
+		thumb.Etag = etag
+		thumb.LastModified = modified
+		return thumb
+	}
+
+	tests := []struct {
+		name    string
+		refresh *proto.Thumbnail
+		want    int64
+	}{
+		{name: "Same image", refresh: versioned("v1", 200), want: 100},
+		{name: "Changed image", refresh: versioned("v2", 200), want: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.NewMemoryCache(10, 0)
+			c.SetSeries(ctx, versioned("v1", 100))
+			c.SetSeries(ctx, tt.refresh)
+
+			resp, _ := c.Get(ctx, variant)
+			if got := resp.GetThumbnail().GetLastModified(); got != tt.want {
+				t.Errorf("LastModified = %d, want %d", got, tt.want)
+			}
+			if tt.refresh.GetLastModified() != 200 {
+				t.Errorf("SetSeries() modified stored thumbnail")
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/cache"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+func TestTieredCachePromotion(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		hot  = cache.NewMemoryCache(0, 30)
+		cold = cache.NewMemoryCache(0, 0)
+		c    = cache.NewTieredCache(hot, cold)
+	)
+
+	// Cold only
+	cold.SetSeries(ctx, newThumbnail("aaaaaaaaaaa", proto.Resolution_HIGH))
+
+	if resp, _ := c.Get(ctx, utils.Variant{ID: "aaaaaaaaaaa"}); resp == nil {
+		t.Fatal("Get() missed cold thumbnail")
+	}
+	if hot.Len() != 1 {
+		t.Fatalf("cold hit isn't promoted; hot len = %d", hot.Len())
+	}
+	if resp, _ := c.Get(ctx, utils.Variant{ID: "aaaaaaaaaaa"}); resp == nil {
+		t.Fatal("Get() missed hot thumbnail")
+	}
+	if resp, _ := c.Get(ctx, utils.Variant{ID: "bbbbbbbbbbb"}); resp != nil {
+		t.Fatal("Get() found not cached thumbnail")
+	}
+
+	stats := c.Stats()
+	if stats[0].Hits != 1 || stats[0].Misses != 2 || stats[1].Hits != 1 || stats[1].Misses != 1 {
+		t.Errorf("Stats() = %v", stats)
+	}
+
+	// Byte budget of hot tier fits one thumbnail of 11 + 11 bytes
+	c.SetSeries(ctx,
+		newThumbnail("bbbbbbbbbbb", proto.Resolution_HIGH),
+		newThumbnail("ccccccccccc", proto.Resolution_HIGH),
+	)
+	if hot.Len() != 1 || c.Demoted() != 2 || cold.Len() != 3 {
+		t.Errorf("hot len = %d; demoted = %d; cold len = %d", hot.Len(), c.Demoted(), cold.Len())
+	}
+}
+
+// brokenCache fails every lookup
+type brokenCache struct {
+	cache.Cache
+}
+
+func (brokenCache) Get(ctx context.Context, variant utils.Variant) (*proto.ThumbnailResponse, error) {
+	return nil, cache.ErrUnavailable
+}
+
+func (brokenCache) GetSeries(ctx context.Context, variants ...utils.Variant) (
+	[]*proto.ThumbnailResponse, []utils.Variant, error) {
+	return nil, variants, cache.ErrUnavailable
+}
+
+func TestTieredCacheColdError(t *testing.T) {
+	var (
+		ctx = context.Background()
+		hot = cache.NewMemoryCache(0, 0)
+		c   = cache.NewTieredCache(hot, brokenCache{})
+	)
+	hot.SetSeries(ctx, newThumbnail("aaaaaaaaaaa", proto.Resolution_HIGH))
+
+	if _, err := c.Get(ctx, utils.Variant{ID: "bbbbbbbbbbb"}); !errors.Is(err, cache.ErrUnavailable) {
+		t.Errorf("Get() error = %v, want %v", err, cache.ErrUnavailable)
+	}
+
+	hits, misses, err := c.GetSeries(ctx, utils.Variant{ID: "aaaaaaaaaaa"}, utils.Variant{ID: "bbbbbbbbbbb"})
+	if len(hits) != 1 || len(misses) != 1 || !errors.Is(err, cache.ErrUnavailable) {
+		t.Errorf("GetSeries() = %d hits, %v misses, error %v", len(hits), misses, err)
+	}
+}
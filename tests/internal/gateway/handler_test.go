@@ -0,0 +1,185 @@
+package gateway_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/gateway"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var image = []byte("\xff\xd8\xff\xe0 thumbnail")
+
+// fakeFetcher answers every url of "good" video by thumbnail and others by NOT_FOUND
+type fakeFetcher struct{}
+
+func (fakeFetcher) FetchThumbnail(ctx context.Context, req *proto.GetThumbnailRequest) (
+	*proto.ThumbnailResponse, error) {
+	if req.GetUrl() == "broken" {
+		return nil, utils.NewStatusError(codes.Unavailable, proto.ErrorCode_CACHE_ERROR, "cache is down")
+	}
+	if req.GetUrl() != "good" {
+		return utils.NewErrorThumbnailResponse(req.GetUrl(), proto.ErrorCode_NOT_FOUND, "not found"), nil
+	}
+	thumb := &proto.Thumbnail{
+		Id:         "good",
+		File:       image,
+		MimeType:   "image/jpeg",
+		Resolution: req.GetResolution(),
+		FetchedAt:  time.Now().Unix(),
+		Etag:       "0123abcd",
+	}
+	if utils.NewVariant("good", req).NotModified(thumb) {
+		thumb.File = nil
+		thumb.NotModified = true
+	}
+
+	return &proto.ThumbnailResponse{
+		Content: &proto.ThumbnailResponse_Thumbnail{
+			Thumbnail: thumb,
+		},
+		RequestedUrl: req.GetUrl(),
+	}, nil
+}
+
+func (f fakeFetcher) FetchThumbnailList(ctx context.Context, reqList *proto.ListThumbnailRequest) (
+	[]*proto.ThumbnailResponse, error) {
+	var responses []*proto.ThumbnailResponse
+	for index, req := range reqList.GetRequests() {
+		resp, _ := f.FetchThumbnail(ctx, req)
+		resp.RequestIndex = int32(index)
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+func (f fakeFetcher) StreamThumbnailList(ctx context.Context, reqList *proto.ListThumbnailRequest,
+	send func(*proto.ThumbnailResponse) error) error {
+	return nil
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	gateway.NewGateway(fakeFetcher{}, time.Hour).Register(mux)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetThumbnail(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name        string
+		query       string
+		status      int
+		contentType string
+	}{
+		{name: "Test #1", query: "?url=good&resolution=high", status: http.StatusOK, contentType: "image/jpeg"},
+		{name: "Test #2", query: "?url=missing", status: http.StatusNotFound, contentType: "application/json"},
+		{name: "Test #3", query: "?url=good&resolution=huge", status: http.StatusBadRequest, contentType: "application/json"},
+		{name: "Test #4", query: "?url=good&width=wide", status: http.StatusBadRequest, contentType: "application/json"},
+		{name: "Test #5", query: "", status: http.StatusBadRequest, contentType: "application/json"},
+		{name: "Test #6", query: "?url=broken", status: http.StatusServiceUnavailable, contentType: "application/json"},
+		{name: "Test #7", query: "?url=good&include_file=false", status: http.StatusOK, contentType: "application/json"},
+		{name: "Test #8", query: "?url=good&include_file=maybe", status: http.StatusBadRequest, contentType: "application/json"},
+		{name: "Test #9", query: "?url=good&parts=statistics,likes", status: http.StatusBadRequest, contentType: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + gateway.ThumbnailPath + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("GET status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("GET Content-Type = %s, want %s", got, tt.contentType)
+			}
+		})
+	}
+}
+
+func TestGetThumbnailCaching(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + gateway.ThumbnailPath + "?url=good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	etag := resp.Header.Get("ETag")
+	if etag != `"0123abcd"` || !strings.HasPrefix(resp.Header.Get("Cache-Control"), "public, max-age=") {
+		t.Fatalf("GET headers = %v", resp.Header)
+	}
+	if string(body) != string(image) {
+		t.Errorf("GET body = %q, want %q", body, image)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+gateway.ThumbnailPath+"?url=good", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("GET with If-None-Match status = %d, want %d", resp.StatusCode, http.StatusNotModified)
+	}
+}
+
+func TestBatchThumbnails(t *testing.T) {
+	server := newTestServer(t)
+
+	body := `{"Requests": [{"url": "good", "resolution": "MEDIUM"}, {"url": "missing"}]}`
+	resp, err := http.Post(server.URL+gateway.BatchPath, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	data, _ := io.ReadAll(resp.Body)
+	var list proto.ListThumbnailResponse
+	if err := protojson.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+
+	thumbs := list.GetThumbnails()
+	if len(thumbs) != 2 {
+		t.Fatalf("POST responses = %d, want 2", len(thumbs))
+	}
+	if got := thumbs[0].GetThumbnail().GetResolution(); got != proto.Resolution_MEDIUM {
+		t.Errorf("responses[0] resolution = %s, want MEDIUM", got)
+	}
+	if got := thumbs[1].GetError().GetCode(); got != proto.ErrorCode_NOT_FOUND {
+		t.Errorf("responses[1] code = %s, want NOT_FOUND", got)
+	}
+
+	resp, err = http.Post(server.URL+gateway.BatchPath, "application/json", strings.NewReader("{"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST of invalid body status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,85 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	igrpc "github.com/fluxx1on/thumbnails_microservice/internal/grpc"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/internal/routing"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+	"google.golang.org/grpc"
+)
+
+// fakeFetcher streams NOT_FOUND response of every request until send fails
+type fakeFetcher struct {
+	routing.ThumbnailFetcher
+}
+
+func (fakeFetcher) StreamThumbnailList(ctx context.Context, reqList *proto.ListThumbnailRequest,
+	send func(*proto.ThumbnailResponse) error) error {
+	for index, req := range reqList.GetRequests() {
+		resp := utils.NewErrorThumbnailResponse(req.GetUrl(), proto.ErrorCode_NOT_FOUND, "not found")
+		resp.RequestIndex = int32(index)
+		if err := send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeStream keeps sent messages and fails after limit of them
+type fakeStream struct {
+	grpc.ServerStream
+
+	limit int
+	sent  []*proto.ThumbnailResponse
+}
+
+var errClosed = errors.New("stream is closed")
+
+func (s *fakeStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeStream) Send(resp *proto.ThumbnailResponse) error {
+	if len(s.sent) == s.limit {
+		return errClosed
+	}
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+func TestStreamThumbnails(t *testing.T) {
+	reqList := &proto.ListThumbnailRequest{Requests: []*proto.GetThumbnailRequest{
+		{Url: "first"}, {Url: "second"}, {Url: "third"},
+	}}
+
+	tests := []struct {
+		name    string
+		limit   int
+		wantErr error
+	}{
+		{name: "Test #1", limit: 3, wantErr: nil},
+		{name: "Test #2", limit: 1, wantErr: errClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &fakeStream{limit: tt.limit}
+
+			err := igrpc.NewThumbnailService(fakeFetcher{}).StreamThumbnails(reqList, stream)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StreamThumbnails() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(stream.sent) != tt.limit {
+				t.Fatalf("StreamThumbnails() sent %d messages, want %d", len(stream.sent), tt.limit)
+			}
+			for index, resp := range stream.sent {
+				if int(resp.GetRequestIndex()) != index {
+					t.Errorf("message #%d request_index = %d", index, resp.GetRequestIndex())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package grpc_test
+
+import (
+	"testing"
+
+	igrpc "github.com/fluxx1on/thumbnails_microservice/internal/grpc"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+func TestResponseStat(t *testing.T) {
+	var (
+		thumbnail = &proto.ThumbnailResponse{
+			Content: &proto.ThumbnailResponse_Thumbnail{Thumbnail: &proto.Thumbnail{Title: "cached"}},
+		}
+		failed = utils.NewErrorThumbnailResponse("Gmlh0NrvzP0", proto.ErrorCode_NOT_FOUND, "not found")
+		stat   igrpc.ResponseStat
+	)
+
+	stat.Add(thumbnail)
+	stat.Add(failed, thumbnail)
+
+	want := "successes: 2; errors: 1."
+	if got := stat.String(); got != want {
+		t.Errorf("ResponseStat = %q, want %q", got, want)
+	}
+	if got := igrpc.GetResponseStat(thumbnail, failed, thumbnail); got != want {
+		t.Errorf("GetResponseStat() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package media_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/media"
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
+	"github.com/fluxx1on/thumbnails_microservice/libs/signing"
+)
+
+func TestHandler(t *testing.T) {
+	store := blobstore.NewFileStore(t.TempDir())
+	previous := blobstore.Media
+	blobstore.Media = store
+	t.Cleanup(func() { blobstore.Media = previous })
+
+	image := []byte("\xff\xd8\xff\xe0 thumbnail")
+	digest, err := store.Put(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(nil)
+	defer server.Close()
+
+	var (
+		signer  = signing.NewURLSigner([]byte("secret"), server.URL, time.Minute)
+		other   = signing.NewURLSigner([]byte("other"), server.URL, time.Minute)
+		mux     = http.NewServeMux()
+		link, _ = signer.Sign(digest, time.Now())
+		gone, _ = signer.Sign(digest, time.Now().Add(-time.Hour))
+		forged  = strings.Replace(link, "signature=", "signature=0", 1)
+	)
+	mux.Handle(signing.MediaPath, media.NewHandler(signer))
+	server.Config.Handler = mux
+
+	missing, _ := signer.Sign(blobstore.Digest([]byte("missing")), time.Now())
+	foreign, _ := other.Sign(digest, time.Now())
+
+	tests := []struct {
+		name   string
+		link   string
+		status int
+	}{
+		{name: "Test #1", link: link, status: http.StatusOK},
+		{name: "Test #2", link: gone, status: http.StatusForbidden},
+		{name: "Test #3", link: forged, status: http.StatusForbidden},
+		{name: "Test #4", link: foreign, status: http.StatusForbidden},
+		{name: "Test #5", link: missing, status: http.StatusNotFound},
+		{name: "Test #6", link: server.URL + signing.MediaPath + "../etc/passwd", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(tt.link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("GET status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status == http.StatusOK {
+				if data, _ := io.ReadAll(resp.Body); string(data) != string(image) {
+					t.Errorf("GET body = %q, want %q", data, image)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package routing_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/internal/routing"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+func thumbnailOf(variant utils.Variant) *proto.ThumbnailResponse {
+	return &proto.ThumbnailResponse{
+		Content: &proto.ThumbnailResponse_Thumbnail{
+			Thumbnail: &proto.Thumbnail{
+				Id:         variant.ID,
+				Resolution: variant.Resolution,
+				Available:  []proto.Resolution{variant.Resolution},
+				File:       []byte("jpeg"),
+				Metadata:   &proto.VideoMetadata{Parts: variant.Parts.List()},
+			},
+		},
+	}
+}
+
+// collect gathers results of Coalescer.Do
+func collect(ctx context.Context, coalescer *routing.Coalescer, fetch routing.FetchFunc, variants ...utils.Variant) (
+	[]*proto.ThumbnailResponse, []youtube.Failure) {
+	var (
+		responses []*proto.ThumbnailResponse
+		failures  []youtube.Failure
+	)
+	coalescer.Do(ctx, fetch, func(variant utils.Variant, resp *proto.ThumbnailResponse, err error) error {
+		if err != nil {
+			failures = append(failures, youtube.Failure{Variant: variant, Err: err})
+		} else {
+			responses = append(responses, resp)
+		}
+		return nil
+	}, variants...)
+	return responses, failures
+}
+
+func TestCoalescerDo(t *testing.T) {
+	const requests = 50
+
+	var (
+		coalescer = routing.NewCoalescer()
+		calls     atomic.Int32
+		release   = make(chan struct{})
+		variant   = utils.Variant{ID: "QFxZlKb7W2k", Resolution: proto.Resolution_HIGH, Parts: utils.NewParts()}
+	)
+
+	fetch := func(ctx context.Context, resolved youtube.ResolveFunc, variants ...utils.Variant) {
+		calls.Add(1)
+		<-release
+
+		for _, v := range variants {
+			resolved(v, thumbnailOf(v), nil)
+		}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		answered atomic.Int32
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses, failures := collect(context.Background(), coalescer, fetch, variant)
+			if len(responses) == 1 && len(failures) == 0 && variant.Answers(responses[0].GetThumbnail()) {
+				answered.Add(1)
+			}
+		}()
+	}
+
+	// Every request joins before the first fetch is finished
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		stats := coalescer.Stats()
+		if stats.Fetched+stats.Coalesced == requests {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Do() stats = %v, want %d requests", stats, requests)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("fetch calls = %d, want 1", got)
+	}
+	if got := answered.Load(); got != requests {
+		t.Errorf("answered requests = %d, want %d", got, requests)
+	}
+	if stats := coalescer.Stats(); stats.Coalesced != requests-1 {
+		t.Errorf("Stats() = %v, want %d coalesced", stats, requests-1)
+	}
+}
+
+func TestCoalescerFailure(t *testing.T) {
+	var (
+		coalescer = routing.NewCoalescer()
+		release   = make(chan struct{})
+		started   = make(chan struct{})
+		variant   = utils.Variant{ID: "private", Resolution: proto.Resolution_HIGH}
+	)
+
+	fetch := func(ctx context.Context, resolved youtube.ResolveFunc, variants ...utils.Variant) {
+		close(started)
+		<-release
+		resolved(variants[0], nil, youtube.ErrPrivate)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		collect(context.Background(), coalescer, fetch, variant)
+	}()
+	<-started
+
+	// Joined variant differs from fetched one by request fields
+	joined := variant
+	joined.IfNoneMatch = "etag"
+	go func() {
+		for coalescer.Stats().Coalesced == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+	}()
+
+	responses, failures := collect(context.Background(), coalescer, fetch, joined)
+	<-done
+
+	if len(responses) != 0 || len(failures) != 1 {
+		t.Fatalf("Do() = %d responses, %d failures, want 0 and 1", len(responses), len(failures))
+	}
+	if failures[0].Variant != joined || youtube.ErrorCode(failures[0].Err) != proto.ErrorCode_PRIVATE {
+		t.Errorf("Do() failure = %v", failures[0])
+	}
+}
+
+func TestCoalescerLeaderCanceled(t *testing.T) {
+	var (
+		coalescer = routing.NewCoalescer()
+		release   = make(chan struct{})
+		started   = make(chan struct{})
+		fetchErr  = make(chan error, 1)
+		variant   = utils.Variant{ID: "QFxZlKb7W2k", Resolution: proto.Resolution_HIGH, Parts: utils.NewParts()}
+	)
+
+	fetch := func(ctx context.Context, resolved youtube.ResolveFunc, variants ...utils.Variant) {
+		close(started)
+		<-release
+		fetchErr <- ctx.Err()
+		resolved(variants[0], thumbnailOf(variants[0]), nil)
+	}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan []youtube.Failure)
+	go func() {
+		_, failures := collect(leaderCtx, coalescer, fetch, variant)
+		leaderDone <- failures
+	}()
+	<-started
+
+	waiterDone := make(chan []*proto.ThumbnailResponse)
+	go func() {
+		responses, _ := collect(context.Background(), coalescer, fetch, variant)
+		waiterDone <- responses
+	}()
+	for coalescer.Stats().Coalesced == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Leader gives up while flight is still running
+	cancel()
+	if failures := <-leaderDone; len(failures) != 1 {
+		t.Errorf("Do() of canceled leader = %d failures, want 1", len(failures))
+	}
+	close(release)
+
+	if err := <-fetchErr; err != nil {
+		t.Errorf("fetch context error = %v, want nil", err)
+	}
+	if responses := <-waiterDone; len(responses) != 1 || !variant.Answers(responses[0].GetThumbnail()) {
+		t.Errorf("Do() of waiter = %v, want thumbnail", responses)
+	}
+}
+
+func TestCoalescerAbandoned(t *testing.T) {
+	var (
+		coalescer = routing.NewCoalescer()
+		calls     atomic.Int32
+		started   = make(chan struct{}, 2)
+		fetchErr  = make(chan error, 2)
+		variant   = utils.Variant{ID: "QFxZlKb7W2k", Resolution: proto.Resolution_HIGH, Parts: utils.NewParts()}
+	)
+
+	// The first fetch lasts till its context is canceled
+	fetch := func(ctx context.Context, resolved youtube.ResolveFunc, variants ...utils.Variant) {
+		if calls.Add(1) == 1 {
+			started <- struct{}{}
+			<-ctx.Done()
+			fetchErr <- ctx.Err()
+			resolved(variants[0], nil, ctx.Err())
+			return
+		}
+		resolved(variants[0], thumbnailOf(variants[0]), nil)
+	}
+
+	var (
+		leaderCtx, cancelLeader = context.WithCancel(context.Background())
+		waiterCtx, cancelWaiter = context.WithCancel(context.Background())
+		done                    = make(chan struct{}, 2)
+	)
+	go func() {
+		collect(leaderCtx, coalescer, fetch, variant)
+		done <- struct{}{}
+	}()
+	<-started
+	go func() {
+		collect(waiterCtx, coalescer, fetch, variant)
+		done <- struct{}{}
+	}()
+	for coalescer.Stats().Coalesced == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Flight keeps running while any call waits for it
+	cancelLeader()
+	<-done
+	select {
+	case err := <-fetchErr:
+		t.Fatalf("fetch is canceled with %v while waiter is left", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancelWaiter()
+	<-done
+	select {
+	case err := <-fetchErr:
+		if err != context.Canceled {
+			t.Errorf("fetch context error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetch isn't canceled after all calls left")
+	}
+
+	// Canceled flight isn't joined by new calls
+	responses, failures := collect(context.Background(), coalescer, fetch, variant)
+	if len(responses) != 1 || len(failures) != 0 {
+		t.Errorf("Do() after abandoned flight = %d responses, %d failures, want 1 and 0", len(responses), len(failures))
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("fetch calls = %d, want 2", got)
+	}
+}
@@ -0,0 +1,152 @@
+package routing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/cache"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/imaging"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+func TestFetchThumbnailList(t *testing.T) {
+	tests := []struct {
+		name string
+		urls []string
+		want []want
+	}{
+		{
+			name: "Test #1",
+			urls: []string{
+				"https://www.youtube.com/watch?v=" + missingID,
+				"https://youtu.be/" + uploadedID,
+				"https://www.youtube.com/watch?v=" + cachedID,
+				"bad url",
+				"https://youtu.be/" + uploadedID,
+				cachedID,
+				"bad url",
+			},
+			want: []want{
+				{code: proto.ErrorCode_NOT_FOUND},
+				{source: "api"},
+				{source: "cache"},
+				{code: proto.ErrorCode_INVALID_URL},
+				{source: "api"},
+				{source: "cache"},
+				{code: proto.ErrorCode_INVALID_URL},
+			},
+		},
+		{
+			name: "Test #2",
+			urls: []string{uploadedID, uploadedID, uploadedID},
+			want: []want{{source: "api"}, {source: "api"}, {source: "api"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newFetchService(fakeCache{}, &fakeUpstream{})
+
+			responses, err := service.FetchThumbnailList(context.Background(), listRequest(tt.urls...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(responses) != len(tt.urls) {
+				t.Fatalf("FetchThumbnailList() = %d responses, want %d", len(responses), len(tt.urls))
+			}
+			for index, w := range tt.want {
+				checkResponse(t, responses[index], index, tt.urls[index], w)
+			}
+		})
+	}
+}
+
+func TestFetchCacheError(t *testing.T) {
+	var (
+		upstream = &fakeUpstream{}
+		service  = newFetchService(fakeCache{err: cache.ErrUnavailable}, upstream)
+		urls     = []string{cachedID, "bad url", uploadedID}
+	)
+
+	resp, err := service.FetchThumbnail(context.Background(), &proto.GetThumbnailRequest{Url: cachedID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetError().GetCode(); got != proto.ErrorCode_CACHE_ERROR {
+		t.Errorf("FetchThumbnail() code = %s, want CACHE_ERROR", got)
+	}
+
+	responses, err := service.FetchThumbnailList(context.Background(), listRequest(urls...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, w := range []want{
+		{code: proto.ErrorCode_CACHE_ERROR},
+		{code: proto.ErrorCode_INVALID_URL},
+		{code: proto.ErrorCode_CACHE_ERROR},
+	} {
+		checkResponse(t, responses[index], index, urls[index], w)
+	}
+
+	if len(upstream.downloaded) != 0 {
+		t.Errorf("%d videos downloaded while cache is unavailable", len(upstream.downloaded))
+	}
+}
+
+// imagelessCache answers like Redis cache for client that has the image: without reading it
+type imagelessCache struct {
+	fakeCache
+}
+
+const cachedEtag = "0123abcd"
+
+func (c imagelessCache) Get(ctx context.Context, variant utils.Variant) (*proto.ThumbnailResponse, error) {
+	resp, err := c.fakeCache.Get(ctx, variant)
+	if thumb := resp.GetThumbnail(); thumb != nil {
+		thumb.File = nil
+		thumb.Etag = cachedEtag
+	}
+	return resp, err
+}
+
+func (c imagelessCache) GetSeries(ctx context.Context, variants ...utils.Variant) (
+	[]*proto.ThumbnailResponse, []utils.Variant, error) {
+	var (
+		hits   []*proto.ThumbnailResponse
+		misses []utils.Variant
+	)
+	for _, variant := range variants {
+		if resp, _ := c.Get(ctx, variant); resp != nil {
+			hits = append(hits, resp)
+		} else {
+			misses = append(misses, variant)
+		}
+	}
+	return hits, misses, nil
+}
+
+func TestFetchThumbnailNotModified(t *testing.T) {
+	derived := imaging.Spec{Width: 100}
+	reqList := &proto.ListThumbnailRequest{Requests: []*proto.GetThumbnailRequest{
+		{Url: cachedID, IfNoneMatch: `W/"` + cachedEtag + `"`},
+		{Url: cachedID, Width: 100, IfNoneMatch: `"ffff", "` + cachedEtag + derived.Suffix() + `"`},
+	}}
+	wantEtags := []string{cachedEtag, cachedEtag + derived.Suffix()}
+
+	upstream := &fakeUpstream{}
+	service := newFetchService(imagelessCache{}, upstream)
+
+	responses, err := service.FetchThumbnailList(context.Background(), reqList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, resp := range responses {
+		thumb := resp.GetThumbnail()
+		if !thumb.GetNotModified() || len(thumb.GetFile()) != 0 || thumb.GetEtag() != wantEtags[index] {
+			t.Errorf("response #%d = %v, want not modified %s", index, resp, wantEtags[index])
+		}
+	}
+	if len(upstream.downloaded) != 0 {
+		t.Errorf("FetchThumbnailList() downloaded %d videos for client having them", len(upstream.downloaded))
+	}
+}
@@ -0,0 +1,266 @@
+package routing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/cmd/config"
+	"github.com/fluxx1on/thumbnails_microservice/external/youtube"
+	"github.com/fluxx1on/thumbnails_microservice/internal/cache"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/internal/routing"
+	"github.com/fluxx1on/thumbnails_microservice/internal/scheduler"
+	"github.com/fluxx1on/thumbnails_microservice/libs/quota"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+const (
+	cachedID   = "QFxZlKb7W2k"
+	uploadedID = "D0St2LH158Q"
+	missingID  = "Gmlh0NrvzP0"
+)
+
+// sourced is a thumbnail of variant marked by source in its title
+func sourced(variant utils.Variant, source string) *proto.ThumbnailResponse {
+	resp := thumbnailOf(variant)
+	resp.GetThumbnail().Title = source
+	return resp
+}
+
+// fakeCache keeps thumbnails of cachedID. Lookups fail by err if it is set.
+type fakeCache struct {
+	err error
+}
+
+func (c fakeCache) Get(ctx context.Context, variant utils.Variant) (*proto.ThumbnailResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if variant.ID != cachedID {
+		return nil, nil
+	}
+	return sourced(variant, "cache"), nil
+}
+
+func (c fakeCache) GetSeries(ctx context.Context, variants ...utils.Variant) (
+	[]*proto.ThumbnailResponse, []utils.Variant, error) {
+	if c.err != nil {
+		return nil, variants, c.err
+	}
+
+	var (
+		hits   []*proto.ThumbnailResponse
+		misses []utils.Variant
+	)
+	for _, variant := range variants {
+		if resp, _ := c.Get(ctx, variant); resp != nil {
+			hits = append(hits, resp)
+		} else {
+			misses = append(misses, variant)
+		}
+	}
+	return hits, misses, nil
+}
+
+func (fakeCache) SetSeries(ctx context.Context, thumbnails ...*proto.Thumbnail) {}
+
+// fakeUpstream knows uploadedID only and counts downloaded variants
+type fakeUpstream struct {
+	downloaded []utils.Variant
+}
+
+func (u *fakeUpstream) StreamVideoThumbnail(ctx context.Context, resolved youtube.ResolveFunc,
+	variants ...utils.Variant) {
+	for _, variant := range variants {
+		u.downloaded = append(u.downloaded, variant)
+		if variant.ID == uploadedID {
+			resolved(variant, sourced(variant, "api"), nil)
+		} else {
+			resolved(variant, nil, youtube.ErrNotFound)
+		}
+	}
+}
+
+func (u *fakeUpstream) QuotaLevel() quota.Level {
+	return quota.LevelNormal
+}
+
+func newFetchService(c cache.Cache, upstream routing.Upstream) *routing.ThumbnailFetchService {
+	queue := scheduler.NewCacheQueue(context.Background(), c)
+	return routing.NewThumbnailFetchService(queue, upstream, &config.CacheBackend{})
+}
+
+func listRequest(urls ...string) *proto.ListThumbnailRequest {
+	reqList := &proto.ListThumbnailRequest{}
+	for _, url := range urls {
+		reqList.Requests = append(reqList.Requests, &proto.GetThumbnailRequest{Url: url})
+	}
+	return reqList
+}
+
+// want is an expected response: title of thumbnail source or error code
+type want struct {
+	source string
+	code   proto.ErrorCode
+}
+
+func checkResponse(t *testing.T, resp *proto.ThumbnailResponse, index int, url string, w want) {
+	t.Helper()
+
+	if resp == nil {
+		t.Fatalf("response #%d is missing", index)
+	}
+	if int(resp.GetRequestIndex()) != index || resp.GetRequestedUrl() != url {
+		t.Errorf("response #%d correlation = %d %q, want %d %q",
+			index, resp.GetRequestIndex(), resp.GetRequestedUrl(), index, url)
+	}
+	if w.source != "" {
+		if got := resp.GetThumbnail().GetTitle(); got != w.source {
+			t.Errorf("response #%d source = %q (%v), want %q", index, got, resp.GetError(), w.source)
+		}
+		return
+	}
+	if got := resp.GetError().GetCode(); got != w.code {
+		t.Errorf("response #%d code = %s, want %s", index, got, w.code)
+	}
+}
+
+func TestStreamThumbnailList(t *testing.T) {
+	tests := []struct {
+		name       string
+		urls       []string
+		want       []want
+		downloaded int
+	}{
+		{
+			name: "Test #1",
+			urls: []string{
+				"https://youtu.be/" + uploadedID,
+				"not a url",
+				"https://www.youtube.com/watch?v=" + cachedID,
+				"https://www.youtube.com/watch?v=" + missingID,
+			},
+			want: []want{
+				{source: "api"},
+				{code: proto.ErrorCode_INVALID_URL},
+				{source: "cache"},
+				{code: proto.ErrorCode_NOT_FOUND},
+			},
+			downloaded: 2,
+		},
+		{
+			name:       "Test #2",
+			urls:       []string{cachedID, "https://vimeo.com/1"},
+			want:       []want{{source: "cache"}, {code: proto.ErrorCode_INVALID_URL}},
+			downloaded: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				upstream = &fakeUpstream{}
+				service  = newFetchService(fakeCache{}, upstream)
+				received = make(map[int]*proto.ThumbnailResponse)
+			)
+
+			err := service.StreamThumbnailList(context.Background(), listRequest(tt.urls...),
+				func(resp *proto.ThumbnailResponse) error {
+					index := int(resp.GetRequestIndex())
+					if _, ok := received[index]; ok {
+						t.Errorf("response #%d is sent twice", index)
+					}
+					received[index] = resp
+					return nil
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(received) != len(tt.urls) {
+				t.Errorf("StreamThumbnailList() sent %d responses, want %d", len(received), len(tt.urls))
+			}
+			for index, w := range tt.want {
+				checkResponse(t, received[index], index, tt.urls[index], w)
+			}
+			if len(upstream.downloaded) != tt.downloaded {
+				t.Errorf("StreamThumbnailList() downloaded %d videos, want %d", len(upstream.downloaded), tt.downloaded)
+			}
+		})
+	}
+}
+
+func TestStreamThumbnailListSendError(t *testing.T) {
+	var (
+		errSend  = errors.New("stream is closed")
+		upstream = &fakeUpstream{}
+		service  = newFetchService(fakeCache{}, upstream)
+		sent     int
+	)
+
+	reqList := listRequest("not a url", cachedID, uploadedID)
+	err := service.StreamThumbnailList(context.Background(), reqList, func(resp *proto.ThumbnailResponse) error {
+		sent++
+		return errSend
+	})
+
+	if !errors.Is(err, errSend) {
+		t.Errorf("StreamThumbnailList() error = %v, want %v", err, errSend)
+	}
+	if sent != 1 {
+		t.Errorf("StreamThumbnailList() sent %d responses after failed Send, want 1", sent)
+	}
+	if len(upstream.downloaded) != 0 {
+		t.Errorf("StreamThumbnailList() downloaded %d videos after failed Send", len(upstream.downloaded))
+	}
+}
+
+// gatedUpstream resolves the next video only after the previous one is sent
+type gatedUpstream struct {
+	fakeUpstream
+	sent chan struct{}
+}
+
+func (u *gatedUpstream) StreamVideoThumbnail(ctx context.Context, resolved youtube.ResolveFunc,
+	variants ...utils.Variant) {
+	for i, variant := range variants {
+		if i != 0 {
+			select {
+			case <-u.sent:
+			case <-time.After(time.Second):
+				resolved(variant, nil, youtube.ErrNotFound)
+				continue
+			}
+		}
+		resolved(variant, sourced(variant, "api"), nil)
+	}
+}
+
+func TestStreamThumbnailListProgressive(t *testing.T) {
+	var (
+		upstream = &gatedUpstream{sent: make(chan struct{}, 3)}
+		service  = newFetchService(fakeCache{}, upstream)
+		urls     = []string{uploadedID, missingID, "https://youtu.be/" + uploadedID}
+		received []*proto.ThumbnailResponse
+	)
+
+	err := service.StreamThumbnailList(context.Background(), listRequest(urls...),
+		func(resp *proto.ThumbnailResponse) error {
+			received = append(received, resp)
+			upstream.sent <- struct{}{}
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != len(urls) {
+		t.Fatalf("StreamThumbnailList() sent %d responses, want %d", len(received), len(urls))
+	}
+	for i, resp := range received {
+		if resp.GetThumbnail().GetTitle() != "api" || int(resp.GetRequestIndex()) != i {
+			t.Errorf("response #%d = %d %v, want thumbnail sent before the next download", i, resp.GetRequestIndex(), resp.GetError())
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package routing_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/fluxx1on/thumbnails_microservice/internal/routing"
@@ -23,6 +24,20 @@ func TestGetQueryID(t *testing.T) {
 		{name: "Test #1", args: args{"https://www.youtube.com/watch?v=Gmlh0NrvzP0&ab_channel=AnthonyGG"}, want: "Gmlh0NrvzP0", wantErr: false},
 		{name: "Test #2", args: args{"https://www.youtube.com/watch?k=Gmlh0NrvzP9&ab_channel=AnthonyGG"}, want: ErrInvalidURL, wantErr: true},
 		{name: "Test #3", args: args{"Negative case"}, want: ErrInvalidURL, wantErr: true},
+		{name: "Short link", args: args{"https://youtu.be/Gmlh0NrvzP0?si=abc"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "Shorts", args: args{"https://www.youtube.com/shorts/Gmlh0NrvzP0"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "Embed", args: args{"https://www.youtube.com/embed/Gmlh0NrvzP0?start=10"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "Live", args: args{"https://www.youtube.com/live/Gmlh0NrvzP0?feature=share"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "Mobile", args: args{"https://m.youtube.com/watch?v=Gmlh0NrvzP0"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "Music", args: args{"https://music.youtube.com/watch?v=Gmlh0NrvzP0&list=RD"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "No cookie", args: args{"https://www.youtube-nocookie.com/embed/Gmlh0NrvzP0"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "No scheme", args: args{"youtube.com/watch?v=Gmlh0NrvzP0"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "Bare ID", args: args{"Gmlh0NrvzP0"}, want: "Gmlh0NrvzP0", wantErr: false},
+		{name: "Foreign host", args: args{"https://vimeo.com/watch?v=Gmlh0NrvzP0"}, want: ErrInvalidURL, wantErr: true},
+		{name: "Short ID", args: args{"https://youtu.be/Gmlh0Nrv"}, want: ErrInvalidURL, wantErr: true},
+		{name: "Bad ID chars", args: args{"https://www.youtube.com/watch?v=Gmlh0Nr$zP0"}, want: ErrInvalidURL, wantErr: true},
+		{name: "Unknown path", args: args{"https://www.youtube.com/channel/Gmlh0NrvzP0"}, want: ErrInvalidURL, wantErr: true},
+		{name: "Empty", args: args{""}, want: ErrInvalidURL, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -37,3 +52,30 @@ func TestGetQueryID(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVideoURLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want error
+	}{
+		{name: "Malformed", src: "https://%zz", want: routing.ErrMalformedURL},
+		{name: "Scheme", src: "ftp://youtube.com/watch?v=Gmlh0NrvzP0", want: routing.ErrMalformedURL},
+		{name: "Host", src: "https://example.com/watch?v=Gmlh0NrvzP0", want: routing.ErrUnsupportedHost},
+		{name: "Missing", src: "https://www.youtube.com/watch", want: routing.ErrMissingID},
+		{name: "Invalid", src: "https://youtu.be/abc", want: routing.ErrInvalidID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := routing.ParseVideoURL(tt.src)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ParseVideoURL() error = %v, want %v", err, tt.want)
+			}
+
+			var urlErr *routing.URLError
+			if !errors.As(err, &urlErr) {
+				t.Errorf("ParseVideoURL() error isn't *URLError: %T", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package blobstore_test
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
+)
+
+func TestStoreGet(t *testing.T) {
+	image := []byte("\xff\xd8\xff\xe0 thumbnail")
+
+	tests := []struct {
+		name   string
+		stored []byte
+		want   []byte
+	}{
+		{name: "Test #1", stored: image, want: image},
+		{name: "Test #2", stored: image[:4], want: nil},
+		{name: "Test #3", stored: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := blobstore.NewFileStore(t.TempDir())
+			digest, err := store.Put(image)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Blob is replaced by stored content to simulate corruption
+			path := store.Path(digest)
+			if tt.stored == nil {
+				os.Remove(path)
+			} else if err := os.WriteFile(path, tt.stored, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got := store.Get(digest)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+			if tt.want == nil {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("Get() kept corrupted blob")
+				}
+			}
+		})
+	}
+}
+
+func TestStoreDeduplication(t *testing.T) {
+	store := blobstore.NewFileStore(t.TempDir())
+
+	first, err1 := store.Put([]byte("image"))
+	second, err2 := store.Put([]byte("image"))
+	other, err3 := store.Put([]byte("other image"))
+	if err1 != nil || err2 != nil || err3 != nil {
+		t.Fatal(err1, err2, err3)
+	}
+
+	if first != second {
+		t.Errorf("Put() of identical images = %s, %s", first, second)
+	}
+	if first == other {
+		t.Errorf("Put() of different images = %s, %s", first, other)
+	}
+}
+
+func TestStoreCollect(t *testing.T) {
+	store := blobstore.NewFileStore(t.TempDir())
+
+	var (
+		referenced, _ = store.Put([]byte("referenced"))
+		orphan, _     = store.Put([]byte("orphan"))
+		recent, _     = store.Put([]byte("recent"))
+	)
+	if err := store.PutDerived([]byte("small"), orphan, "_100x100"); err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	for _, digest := range []string{referenced, orphan} {
+		os.Chtimes(store.Path(digest), old, old)
+	}
+
+	got := store.Collect(time.Minute, func(digests ...string) []string {
+		var unreferenced []string
+		for _, digest := range digests {
+			if digest != referenced {
+				unreferenced = append(unreferenced, digest)
+			}
+		}
+		return unreferenced
+	})
+
+	if want := []string{orphan}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect() = %v, want %v", got, want)
+	}
+	for digest, want := range map[string]bool{referenced: true, orphan: false, recent: true} {
+		if got := store.Get(digest) != nil; got != want {
+			t.Errorf("blob %s kept = %v, want %v", digest, got, want)
+		}
+	}
+	if store.GetDerived(orphan, "_100x100") != nil {
+		t.Errorf("Collect() kept derivative of collected blob")
+	}
+}
@@ -0,0 +1,150 @@
+package blobstore_test
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/libs/blobstore"
+)
+
+func TestSweeper(t *testing.T) {
+	type blob struct {
+		data string
+		age  time.Duration
+	}
+	blobs := []blob{
+		{data: "old", age: 3 * time.Hour},
+		{data: "mid", age: 2 * time.Hour},
+		{data: "new", age: time.Hour},
+	}
+
+	tests := []struct {
+		name   string
+		budget blobstore.Budget
+		want   []string
+	}{
+		{name: "Test #1", budget: blobstore.Budget{}, want: nil},
+		{name: "Test #2", budget: blobstore.Budget{MaxBytes: 9}, want: nil},
+		{name: "Test #3", budget: blobstore.Budget{MaxBytes: 7}, want: []string{"old"}},
+		{name: "Test #4", budget: blobstore.Budget{MaxFiles: 1}, want: []string{"mid", "old"}},
+		{name: "Test #5", budget: blobstore.Budget{MaxBytes: 2}, want: []string{"mid", "new", "old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				store = blobstore.NewFileStore(t.TempDir())
+				names = make(map[string]string)
+			)
+			for _, b := range blobs {
+				digest, err := store.Put([]byte(b.data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				accessed := time.Now().Add(-b.age)
+				if err := os.Chtimes(store.Path(digest), accessed, accessed); err != nil {
+					t.Fatal(err)
+				}
+				names[store.Path(digest)] = b.data
+			}
+
+			var forgotten []string
+			sweeper := blobstore.NewSweeper(store, tt.budget, func(digests ...string) {
+				forgotten = append(forgotten, digests...)
+			})
+
+			var got []string
+			for _, path := range sweeper.Sweep() {
+				got = append(got, names[path])
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("Sweep() left evicted file %s", path)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sweep() = %v, want %v", got, tt.want)
+			}
+			if len(forgotten) != len(got) {
+				t.Errorf("onEvict got %d digests, want %d", len(forgotten), len(got))
+			}
+		})
+	}
+}
+
+func TestSweeperReadAccess(t *testing.T) {
+	var (
+		store   = blobstore.NewFileStore(t.TempDir())
+		digests = make(map[string]string)
+		old     = time.Now().Add(-3 * time.Hour)
+	)
+	for i, data := range []string{"old", "mid", "new"} {
+		digest, err := store.Put([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		accessed := old.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(store.Path(digest), accessed, accessed); err != nil {
+			t.Fatal(err)
+		}
+		digests[data] = digest
+	}
+
+	// Read doesn't write access time to disk
+	if store.Get(digests["old"]) == nil {
+		t.Fatal("Get() missed stored blob")
+	}
+	info, err := os.Stat(store.Path(digests["old"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Errorf("Get() changed modification time to %s", info.ModTime())
+	}
+
+	// The least recently accessed blob is evicted, access times are flushed
+	sweeper := blobstore.NewSweeper(store, blobstore.Budget{MaxFiles: 2}, nil)
+	if got := sweeper.Sweep(); len(got) != 1 || got[0] != store.Path(digests["mid"]) {
+		t.Errorf("Sweep() = %v, want %s", got, store.Path(digests["mid"]))
+	}
+	if info, err := os.Stat(store.Path(digests["old"])); err != nil || !info.ModTime().After(old) {
+		t.Errorf("Sweep() didn't flush access time of read blob")
+	}
+}
+
+func TestSweeperRemovedFile(t *testing.T) {
+	var (
+		store   = blobstore.NewFileStore(t.TempDir())
+		budget  = blobstore.Budget{MaxFiles: 1, Policy: blobstore.EvictLFU}
+		sweeper = blobstore.NewSweeper(store, budget, nil)
+	)
+
+	popular, err := store.Put([]byte("popular"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		store.Get(popular)
+	}
+
+	// Reads of file removed outside of store are forgotten by sweep
+	if err := os.Remove(store.Path(popular)); err != nil {
+		t.Fatal(err)
+	}
+	sweeper.Sweep()
+
+	if _, err := store.Put([]byte("popular")); err != nil {
+		t.Fatal(err)
+	}
+	read, err := store.Put([]byte("read"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Get(read)
+
+	evicted := sweeper.Sweep()
+	if len(evicted) != 1 || evicted[0] != store.Path(popular) {
+		t.Errorf("Sweep() = %v, want restored file without its old reads", evicted)
+	}
+}
@@ -0,0 +1,72 @@
+package imaging_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/imaging"
+)
+
+func newJPEG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTransform(t *testing.T) {
+	src := newJPEG(t, 200, 100)
+
+	tests := []struct {
+		name       string
+		spec       imaging.Spec
+		wantWidth  int
+		wantHeight int
+		wantMime   string
+		wantErr    error
+	}{
+		{name: "Contain", spec: imaging.Spec{Width: 100, Height: 100}, wantWidth: 100, wantHeight: 50, wantMime: imaging.MimeJPEG},
+		{name: "Cover", spec: imaging.Spec{Width: 100, Height: 100, Fit: proto.FitMode_FIT_COVER}, wantWidth: 100, wantHeight: 100, wantMime: imaging.MimeJPEG},
+		{name: "Exact", spec: imaging.Spec{Width: 30, Height: 40, Fit: proto.FitMode_FIT_EXACT}, wantWidth: 30, wantHeight: 40, wantMime: imaging.MimeJPEG},
+		{name: "Width only", spec: imaging.Spec{Width: 50}, wantWidth: 50, wantHeight: 25, wantMime: imaging.MimeJPEG},
+		{name: "PNG", spec: imaging.Spec{Format: proto.ImageFormat_PNG}, wantWidth: 200, wantHeight: 100, wantMime: imaging.MimePNG},
+		{name: "WebP", spec: imaging.Spec{Format: proto.ImageFormat_WEBP}, wantErr: imaging.ErrUnsupportedFormat},
+		{name: "Negative", spec: imaging.Spec{Width: -1}, wantErr: imaging.ErrInvalidSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := imaging.Transform(src, tt.spec)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Transform() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Width != tt.wantWidth || got.Height != tt.wantHeight {
+				t.Errorf("Transform() = %dx%d, want %dx%d", got.Width, got.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if got.MimeType != tt.wantMime {
+				t.Errorf("Transform() mime = %v, want %v", got.MimeType, tt.wantMime)
+			}
+
+			inspected, err := imaging.Inspect(got.Data)
+			if err != nil || inspected.Width != got.Width || inspected.Height != got.Height {
+				t.Errorf("Inspect() = %dx%d, %v", inspected.Width, inspected.Height, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package quota_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/libs/quota"
+)
+
+func TestDay(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{name: "Test #1", now: time.Date(2024, 1, 15, 7, 59, 0, 0, time.UTC), want: "2024-01-14"},
+		{name: "Test #2", now: time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC), want: "2024-01-15"},
+		{name: "Test #3", now: time.Date(2024, 7, 15, 6, 59, 0, 0, time.UTC), want: "2024-07-14"},
+		{name: "Test #4", now: time.Date(2024, 7, 15, 7, 0, 0, 0, time.UTC), want: "2024-07-15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quota.Day(tt.now); got != tt.want {
+				t.Errorf("Day() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracker(t *testing.T) {
+	var (
+		tracker = quota.NewTracker(quota.NewMemoryStore(), 2, 3)
+		now     = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		levels  = []quota.Level{quota.LevelNormal, quota.LevelSoft, quota.LevelHard}
+	)
+
+	for i, want := range levels {
+		if err := tracker.Charge(1, now); err != nil {
+			t.Fatalf("Charge() #%d error = %v", i, err)
+		}
+		if got := tracker.Level(now); got != want {
+			t.Errorf("Level() after %d units = %d, want %d", i+1, got, want)
+		}
+	}
+
+	if err := tracker.Charge(1, now); !errors.Is(err, quota.ErrExceeded) {
+		t.Errorf("Charge() past hard limit error = %v, want %v", err, quota.ErrExceeded)
+	}
+	if got := tracker.Used(now); got != 3 {
+		t.Errorf("Used() = %d, want 3", got)
+	}
+
+	// Usage is reset by Pacific day boundary
+	tomorrow := now.Add(24 * time.Hour)
+	if got := tracker.Level(tomorrow); got != quota.LevelNormal {
+		t.Errorf("Level() of next day = %d, want %d", got, quota.LevelNormal)
+	}
+
+	tracker.Exhaust(tomorrow)
+	if err := tracker.Charge(1, tomorrow); !errors.Is(err, quota.ErrExceeded) {
+		t.Errorf("Charge() after Exhaust() error = %v, want %v", err, quota.ErrExceeded)
+	}
+}
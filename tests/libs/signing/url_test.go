@@ -0,0 +1,52 @@
+package signing_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/libs/signing"
+)
+
+func TestURLSigner(t *testing.T) {
+	var (
+		signer = signing.NewURLSigner([]byte("secret"), "http://localhost:8080/", time.Minute)
+		now    = time.Unix(1700000000, 0)
+	)
+
+	link, expires := signer.Sign("name", now)
+	if !strings.HasPrefix(link, "http://localhost:8080"+signing.MediaPath+"name?") {
+		t.Fatalf("Sign() = %s", link)
+	}
+	if want := now.Add(time.Minute); !expires.Equal(want) {
+		t.Errorf("Sign() expires = %v, want %v", expires, want)
+	}
+
+	parsed, _ := url.Parse(link)
+	tampered := parsed.Query()
+	tampered.Set("expires", "1800000000")
+
+	tests := []struct {
+		name    string
+		file    string
+		query   url.Values
+		now     time.Time
+		wantErr error
+	}{
+		{name: "Test #1", file: "name", query: parsed.Query(), now: now, wantErr: nil},
+		{name: "Test #2", file: "name", query: parsed.Query(), now: now.Add(time.Minute), wantErr: signing.ErrExpired},
+		{name: "Test #3", file: "other", query: parsed.Query(), now: now, wantErr: signing.ErrSignature},
+		{name: "Test #4", file: "name", query: tampered, now: now, wantErr: signing.ErrSignature},
+		{name: "Test #5", file: "name", query: url.Values{}, now: now, wantErr: signing.ErrSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := signer.Verify(tt.file, tt.query, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package upstream_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/libs/upstream"
+)
+
+var policy = upstream.RetryPolicy{
+	Attempts:  3,
+	BaseDelay: time.Millisecond,
+	MaxDelay:  10 * time.Millisecond,
+}
+
+func newClient(breakers *upstream.Breakers, policy upstream.RetryPolicy) *http.Client {
+	return &http.Client{Transport: upstream.NewTransport(breakers, policy)}
+}
+
+func TestTransportRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := calls.Add(1)
+		switch r.URL.Path {
+		case "/flaky":
+			if call < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/limited":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		calls  int32
+	}{
+		{name: "Test #1", path: "/flaky", status: http.StatusOK, calls: 3},
+		{name: "Test #2", path: "/limited", status: http.StatusTooManyRequests, calls: 1},
+		{name: "Test #3", path: "/missing", status: http.StatusNotFound, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			client := newClient(upstream.NewBreakers(upstream.BreakerConfig{Threshold: 5, Cooldown: time.Minute}), policy)
+
+			resp, err := client.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status || calls.Load() != tt.calls {
+				t.Errorf("GET status = %d after %d calls, want %d after %d", resp.StatusCode, calls.Load(), tt.status, tt.calls)
+			}
+		})
+	}
+}
+
+func TestTransportBreaker(t *testing.T) {
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	var (
+		breakers = upstream.NewBreakers(upstream.BreakerConfig{Threshold: 2, Cooldown: 20 * time.Millisecond})
+		client   = newClient(breakers, upstream.RetryPolicy{Attempts: 1})
+		changes  []upstream.State
+	)
+	breakers.OnChange(func(host string, state upstream.State) {
+		changes = append(changes, state)
+	})
+
+	down.Store(true)
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if _, err := client.Get(server.URL); !errors.Is(err, upstream.ErrCircuitOpen) {
+		t.Fatalf("GET of open breaker error = %v, want %v", err, upstream.ErrCircuitOpen)
+	}
+
+	// Probe after cooldown closes breaker
+	down.Store(false)
+	time.Sleep(30 * time.Millisecond)
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	want := []upstream.State{upstream.StateOpen, upstream.StateHalfOpen, upstream.StateClosed}
+	if len(changes) != len(want) {
+		t.Fatalf("breaker changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("breaker changes = %v, want %v", changes, want)
+		}
+	}
+}
+
+func TestTransportCallerDeadline(t *testing.T) {
+	var slow atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slow.Load() {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}))
+	defer server.Close()
+
+	var (
+		breakers = upstream.NewBreakers(upstream.BreakerConfig{Threshold: 2, Cooldown: time.Minute})
+		client   = newClient(breakers, policy)
+	)
+
+	slow.Store(true)
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("GET #%d error = %v, want %v", i, err, context.DeadlineExceeded)
+		}
+		cancel()
+	}
+
+	if state := breakers.For(server.Listener.Addr().String()).State(); state != upstream.StateClosed {
+		t.Fatalf("breaker state after caller deadlines = %s, want closed", state)
+	}
+
+	slow.Store(false)
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransportRetryHook(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		allowed int32
+		calls   int32
+	}{
+		{name: "Test #1", allowed: 2, calls: 3},
+		{name: "Test #2", allowed: 1, calls: 2},
+		{name: "Test #3", allowed: 0, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			client := newClient(upstream.NewBreakers(upstream.BreakerConfig{Threshold: 5, Cooldown: time.Minute}), policy)
+
+			var hooks atomic.Int32
+			ctx := upstream.WithRetryHook(context.Background(), func() error {
+				if hooks.Add(1) > tt.allowed {
+					return errors.New("retry refused")
+				}
+				return nil
+			})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != tt.calls {
+				t.Errorf("GET status = %d after %d calls, want %d after %d",
+					resp.StatusCode, calls.Load(), http.StatusServiceUnavailable, tt.calls)
+			}
+		})
+	}
+}
+
+func TestBreakersConfigure(t *testing.T) {
+	var (
+		breakers = upstream.NewBreakers(upstream.BreakerConfig{}, "known")
+		now      = time.Now()
+	)
+
+	// Breaker without threshold never opens
+	for i := 0; i < 10; i++ {
+		breakers.For("known").Failure(now)
+	}
+	if state := breakers.For("known").State(); state != upstream.StateClosed {
+		t.Fatalf("state without threshold = %s, want closed", state)
+	}
+
+	breakers.Configure(upstream.BreakerConfig{Threshold: 2, Cooldown: time.Minute})
+	for _, host := range []string{"known", "new"} {
+		breaker := breakers.For(host)
+		breaker.Success(now)
+		breaker.Failure(now)
+		breaker.Failure(now)
+		if err := breaker.Allow(now.Add(time.Second)); !errors.Is(err, upstream.ErrCircuitOpen) {
+			t.Errorf("Allow() of %s error = %v, want %v", host, err, upstream.ErrCircuitOpen)
+		}
+	}
+}
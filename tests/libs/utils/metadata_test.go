@@ -0,0 +1,77 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluxx1on/thumbnails_microservice/external/serial"
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "Test #1", src: "PT4M13S", want: 4*time.Minute + 13*time.Second},
+		{name: "Test #2", src: "P1DT2H", want: 26 * time.Hour},
+		{name: "Test #3", src: "P0D", want: 0},
+		{name: "Test #4", src: "P1W", want: 7 * 24 * time.Hour},
+		{name: "Test #5", src: "PT1H2M3S", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{name: "Test #6", src: "4M13S", wantErr: true},
+		{name: "Test #7", src: "P1M", wantErr: true},
+		{name: "Test #8", src: "PT15", wantErr: true},
+		{name: "Test #9", src: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.ParseISODuration(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseISODuration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseISODuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewVideoMetadata(t *testing.T) {
+	item := &serial.Item{
+		Id: "QFxZlKb7W2k",
+		Snippet: serial.SnippetSerializer{
+			ChannelId:   "UC123",
+			PublishedAt: "2020-01-02T03:04:05Z",
+			Tags:        []string{"go", "grpc"},
+		},
+		ContentDetails: &serial.ContentDetailsSerializer{Duration: "PT1M30S"},
+		Statistics:     &serial.StatisticsSerializer{ViewCount: "1024"},
+	}
+
+	meta := utils.NewVideoMetadata(item)
+	if meta.GetChannelId() != "UC123" || len(meta.GetTags()) != 2 {
+		t.Errorf("NewVideoMetadata() snippet = %v", meta)
+	}
+	if want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Unix(); meta.GetPublishedAt() != want {
+		t.Errorf("NewVideoMetadata() published_at = %d, want %d", meta.GetPublishedAt(), want)
+	}
+	if meta.GetDuration() != 90 || meta.GetViewCount() != 1024 || meta.LikeCount != nil {
+		t.Errorf("NewVideoMetadata() duration = %d, views = %d, likes = %v",
+			meta.GetDuration(), meta.GetViewCount(), meta.LikeCount)
+	}
+
+	parts := utils.PartsOf(meta)
+	if !parts.Contains(utils.NewParts(proto.MetadataPart_CONTENT_DETAILS, proto.MetadataPart_STATISTICS)) {
+		t.Errorf("PartsOf() = %v, want all parts", parts.List())
+	}
+
+	item.ContentDetails, item.Statistics = nil, nil
+	if parts := utils.PartsOf(utils.NewVideoMetadata(item)); parts.Has(proto.MetadataPart_STATISTICS) ||
+		!parts.Has(proto.MetadataPart_SNIPPET) {
+		t.Errorf("PartsOf() of snippet = %v", parts.List())
+	}
+}
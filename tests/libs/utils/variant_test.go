@@ -0,0 +1,117 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/fluxx1on/thumbnails_microservice/internal/grpc/proto"
+	"github.com/fluxx1on/thumbnails_microservice/libs/utils"
+)
+
+func TestPickResolution(t *testing.T) {
+	var (
+		all = []proto.Resolution{
+			proto.Resolution_DEFAULT, proto.Resolution_MEDIUM, proto.Resolution_HIGH,
+			proto.Resolution_STANDARD, proto.Resolution_MAXRES,
+		}
+		noMaxres = []proto.Resolution{
+			proto.Resolution_DEFAULT, proto.Resolution_MEDIUM, proto.Resolution_HIGH,
+		}
+	)
+
+	type args struct {
+		available []proto.Resolution
+		preferred proto.Resolution
+		fallback  proto.FallbackPolicy
+	}
+	tests := []struct {
+		name   string
+		args   args
+		want   proto.Resolution
+		wantOk bool
+	}{
+		{name: "Test #1", args: args{all, proto.Resolution_MAXRES, proto.FallbackPolicy_FALLBACK_LOWER}, want: proto.Resolution_MAXRES, wantOk: true},
+		{name: "Test #2", args: args{noMaxres, proto.Resolution_MAXRES, proto.FallbackPolicy_FALLBACK_LOWER}, want: proto.Resolution_HIGH, wantOk: true},
+		{name: "Test #3", args: args{noMaxres, proto.Resolution_MAXRES, proto.FallbackPolicy_FALLBACK_NONE}, want: proto.Resolution_MAXRES, wantOk: false},
+		{name: "Test #4", args: args{noMaxres, proto.Resolution_MAXRES, proto.FallbackPolicy_FALLBACK_HIGHER}, want: proto.Resolution_MAXRES, wantOk: false},
+		{name: "Test #5", args: args{noMaxres[1:], proto.Resolution_DEFAULT, proto.FallbackPolicy_FALLBACK_HIGHER}, want: proto.Resolution_MEDIUM, wantOk: true},
+		{name: "Test #6", args: args{nil, proto.Resolution_HIGH, proto.FallbackPolicy_FALLBACK_LOWER}, want: proto.Resolution_HIGH, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := utils.PickResolution(tt.args.available, tt.args.preferred, tt.args.fallback)
+			if ok != tt.wantOk {
+				t.Errorf("PickResolution() ok = %v, wantOk %v", ok, tt.wantOk)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("PickResolution() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitResolutions(t *testing.T) {
+	src := []proto.Resolution{proto.Resolution_MEDIUM, proto.Resolution_MAXRES}
+
+	got := utils.SplitResolutions(utils.JoinResolutions(src...))
+	if len(got) != len(src) || got[0] != src[0] || got[1] != src[1] {
+		t.Errorf("SplitResolutions() = %v, want %v", got, src)
+	}
+}
+
+func TestVariantNotModified(t *testing.T) {
+	thumb := &proto.Thumbnail{Id: "Gmlh0NrvzP0", Etag: "0123abcd"}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{name: "Test #1", ifNoneMatch: "0123abcd", etag: thumb.Etag, want: true},
+		{name: "Test #2", ifNoneMatch: `"0123abcd"`, etag: thumb.Etag, want: true},
+		{name: "Test #3", ifNoneMatch: `"ffff", W/"0123abcd"`, etag: thumb.Etag, want: true},
+		{name: "Test #4", ifNoneMatch: "*", etag: thumb.Etag, want: true},
+		{name: "Test #5", ifNoneMatch: "ffff", etag: thumb.Etag, want: false},
+		{name: "Test #6", ifNoneMatch: "", etag: thumb.Etag, want: false},
+		{name: "Test #7", ifNoneMatch: "*", etag: "", want: false},
+		{name: "Test #8", ifNoneMatch: `"0123abcd"`, etag: `W/"0123abcd"`, want: true},
+		{name: "Test #9", ifNoneMatch: `W/"0123"`, etag: thumb.Etag, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant := utils.Variant{ID: thumb.Id, IfNoneMatch: tt.ifNoneMatch}
+			if got := variant.NotModified(&proto.Thumbnail{Id: thumb.Id, Etag: tt.etag}); got != tt.want {
+				t.Errorf("NotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariantAnswers(t *testing.T) {
+	var (
+		available = []proto.Resolution{proto.Resolution_MEDIUM, proto.Resolution_HIGH}
+		withFile  = &proto.Thumbnail{Id: "id", Resolution: proto.Resolution_HIGH, Available: available, File: []byte("jpeg")}
+		metadata  = &proto.Thumbnail{Id: "id", Resolution: proto.Resolution_HIGH, Available: available}
+	)
+
+	tests := []struct {
+		name    string
+		variant utils.Variant
+		thumb   *proto.Thumbnail
+		want    bool
+	}{
+		{name: "Test #1", variant: utils.Variant{ID: "id", Resolution: proto.Resolution_HIGH}, thumb: withFile, want: true},
+		{name: "Test #2", variant: utils.Variant{ID: "id", Resolution: proto.Resolution_HIGH}, thumb: metadata, want: false},
+		{name: "Test #3", variant: utils.Variant{ID: "id", Resolution: proto.Resolution_HIGH, MetadataOnly: true}, thumb: metadata, want: true},
+		{name: "Test #4", variant: utils.Variant{ID: "id", Resolution: proto.Resolution_HIGH, MetadataOnly: true}, thumb: withFile, want: true},
+		{name: "Test #5", variant: utils.Variant{ID: "id", Resolution: proto.Resolution_MEDIUM}, thumb: withFile, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.variant.Answers(tt.thumb); got != tt.want {
+				t.Errorf("Answers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}